@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"os"
 	"strings"
 
 	"github.com/pkg/browser"
@@ -60,6 +61,52 @@ corectl meta --app my-app.qvf`,
 	},
 }
 
+var metaSnapshotCmd = withLocalFlags(&cobra.Command{
+	Use:   "snapshot",
+	Args:  cobra.ExactArgs(0),
+	Short: "Save a snapshot of the data model",
+	Long: `Save a snapshot of the data model with table names, row counts, field cardinalities, byte sizes and associations.
+The byte sizes are saved for reference and are not compared. The snapshot is printed if no --out file is given. Use 'corectl meta compare' to compare the data model with a snapshot.`,
+	Example: `corectl meta snapshot --out model.json`,
+
+	Run: func(ccmd *cobra.Command, args []string) {
+		state := internal.PrepareEngineState(rootCtx, headers, tlsClientConfig, false, false)
+		engine := internal.GetEngineURL()
+		data := internal.GetModelMetadata(rootCtx, state.Doc, state.AppID, engine, headers, tlsClientConfig, false)
+		snapshot := internal.CreateModelSnapshot(state.AppName, data)
+		if out := ccmd.Flag("out").Value.String(); out != "" {
+			internal.WriteModelSnapshot(out, snapshot)
+		} else {
+			log.PrintAsJSON(snapshot)
+		}
+	},
+}, "out")
+
+var metaCompareCmd = withLocalFlags(&cobra.Command{
+	Use:   "compare <snapshot-file.json>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Compare the data model with a snapshot",
+	Long: `Compare the data model with a snapshot saved by 'corectl meta snapshot' and exit with a non-zero code if
+tables are missing, the number of rows in a table has dropped by more than --max-row-drop percent or key fields
+and associations have changed. Added tables and fields, removed non-key fields and fields where the number of
+distinct values has dropped by more than --max-row-drop percent are also reported.`,
+	Example: `corectl meta compare model.json
+corectl meta compare model.json --max-row-drop 5`,
+
+	Run: func(ccmd *cobra.Command, args []string) {
+		saved := internal.ReadModelSnapshot(args[0])
+		state := internal.PrepareEngineState(rootCtx, headers, tlsClientConfig, false, false)
+		engine := internal.GetEngineURL()
+		data := internal.GetModelMetadata(rootCtx, state.Doc, state.AppID, engine, headers, tlsClientConfig, false)
+		current := internal.CreateModelSnapshot(state.AppName, data)
+		findings := internal.CompareModelSnapshots(saved, current, viper.GetFloat64("max-row-drop"))
+		printer.PrintLintFindings(findings)
+		if internal.LintFailed(findings, "error") {
+			os.Exit(1)
+		}
+	},
+}, "max-row-drop")
+
 var getValuesCmd = &cobra.Command{
 	Use:     "values <field name>",
 	Args:    cobra.ExactArgs(1),
@@ -119,6 +166,50 @@ corectl eval by "Region" // Returns the values for dimension "Region"`,
 	},
 }
 
+var lineageCmd = withLocalFlags(&cobra.Command{
+	Use:   "lineage <field name>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Print the measures, dimensions, variables and objects that depend on a field",
+	Long: `Print the measures, dimensions, variables and objects that depend on a field.
+All expressions in the app are scanned for references to the field. Variables and master items used in
+expressions are resolved, so an object using a master measure that in turn uses the field is included as well.`,
+	Example: `corectl lineage Sales
+corectl lineage Sales --json
+corectl lineage Sales --dot | dot -Tpng > sales.png`,
+
+	Run: func(ccmd *cobra.Command, args []string) {
+		state := internal.PrepareEngineState(rootCtx, headers, tlsClientConfig, false, false)
+		lineage := internal.GetFieldLineage(rootCtx, state.Doc, args[0])
+		printer.PrintLineage(lineage, viper.GetBool("dot"))
+	},
+}, "dot")
+
+var analyzeUnusedCmd = withLocalFlags(&cobra.Command{
+	Use:   "unused",
+	Args:  cobra.ExactArgs(0),
+	Short: "Print fields, master items and variables that no object uses",
+	Long: `Print fields, master items and variables that no object uses.
+All expressions in measures, dimensions, variables and objects are cross-referenced with the fields in the data model.
+A field, master item or variable is unused if no object uses it, neither directly nor through master items or variables.
+Key fields are never reported as they are needed for the associations. The memory that could be saved by dropping the
+unused fields is estimated from their size.`,
+	Example: `corectl analyze unused
+corectl analyze unused --json`,
+
+	Run: func(ccmd *cobra.Command, args []string) {
+		state := internal.PrepareEngineState(rootCtx, headers, tlsClientConfig, false, false)
+		engine := internal.GetEngineURL()
+		data := internal.GetModelMetadata(rootCtx, state.Doc, state.AppID, engine, headers, tlsClientConfig, false)
+		printer.PrintUnused(internal.FindUnused(rootCtx, state.Doc, data))
+	},
+}, "quiet")
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Analyze how the data model is used by the app",
+	Long:  "Analyze how the data model is used by the app",
+}
+
 var catwalkCmd = withLocalFlags(&cobra.Command{
 	Use:   "catwalk",
 	Args:  cobra.ExactArgs(0),
@@ -159,3 +250,8 @@ corectl catwalk --app my-app.qvf --catwalk-url http://localhost:8080`,
 		}
 	},
 }, "catwalk-url")
+
+func init() {
+	analyzeCmd.AddCommand(analyzeUnusedCmd)
+	getMetaCmd.AddCommand(metaSnapshotCmd, metaCompareCmd)
+}
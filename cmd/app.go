@@ -2,31 +2,88 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/qlik-oss/corectl/internal"
 	"github.com/qlik-oss/corectl/internal/log"
 	"github.com/qlik-oss/corectl/internal/rest"
 	"github.com/qlik-oss/corectl/printer"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
 var listAppsCmd = withLocalFlags(&cobra.Command{
-	Use:     "ls",
-	Args:    cobra.ExactArgs(0),
-	Short:   "Print a list of all apps available in the current engine",
-	Long:    "Print a list of all apps available in the current engine",
-	Example: "corectl app ls",
+	Use:   "ls [<pattern>]",
+	Args:  cobra.MaximumNArgs(1),
+	Short: "Print a list of all apps available in the current engine",
+	Long: `Print a list of all apps available in the current engine
+
+The apps can be limited to the ones whose name, id or title matches a glob pattern and to the ones matching
+--filter expressions, as described for 'corectl app exec'. They can be sorted by name, id, title, size, modified
+or reloaded.
+
+The columns are chosen with --columns among id, name, title, description, size, modified, last-reloaded,
+readonly, thumbnail, published, owner and section-access. Any attribute of the qMeta of the apps can also be
+printed as meta.<attribute>. Which attributes exist, such as description, published and owner, depends on the
+engine. The list is printed as a table, as csv or yaml with --output-format, or as json with --json. All
+columns are included in yaml and json.`,
+	Example: `corectl app ls
+corectl app ls 'sales-*' --sort size --reverse
+corectl app ls --filter 'reloaded<7d' --columns name,owner,published,meta.stream --output-format csv
+corectl app ls --output-format yaml`,
 
 	Run: func(ccmd *cobra.Command, args []string) {
+		pattern := ""
+		if len(args) > 0 {
+			pattern = args[0]
+		}
+		columns, _ := ccmd.Flags().GetStringSlice("columns")
+		if len(columns) == 0 {
+			columns = internal.DefaultAppListColumns
+		}
+		if err := internal.ValidateAppListColumns(columns); err != nil {
+			log.Fatalln(err)
+		}
+		format := ccmd.Flag("output-format").Value.String()
+		if format != "table" && format != "csv" && format != "yaml" {
+			log.Fatalf("unknown output format '%s', expected table, csv or yaml\n", format)
+		}
+		filters := parseAppFilters(ccmd)
 		state := internal.PrepareEngineState(rootCtx, headers, tlsClientConfig, false, true)
-		docList, err := state.Global.GetDocList(rootCtx)
+		apps, err := internal.ListApps(rootCtx, state.Global)
 		if err != nil {
 			log.Fatalf("could not retrieve app list: %s\n", err)
 		}
-		printer.PrintApps(docList, viper.GetBool("bash"))
+		apps, err = internal.FilterAppList(apps, pattern, filters)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if sortBy := ccmd.Flag("sort").Value.String(); sortBy != "" {
+			reverse, _ := ccmd.Flags().GetBool("reverse")
+			if err = internal.SortApps(apps, sortBy, reverse); err != nil {
+				log.Fatalln(err)
+			}
+		}
+		printer.PrintApps(apps, columns, format)
 	},
-}, "quiet")
+}, "quiet", "filter", "sort", "reverse", "columns", "output-format")
+
+// parseAppFilters parses the --filter expressions
+func parseAppFilters(ccmd *cobra.Command) []*internal.AppFilter {
+	filters := []*internal.AppFilter{}
+	expressions, _ := ccmd.Flags().GetStringArray("filter")
+	for _, expression := range expressions {
+		filter, err := internal.ParseAppFilter(expression)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		filters = append(filters, filter)
+	}
+	return filters
+}
 
 var removeAppCmd = withLocalFlags(&cobra.Command{
 	Use:     "rm <app-id>",
@@ -72,6 +129,183 @@ var importAppCmd = withLocalFlags(&cobra.Command{
 	},
 }, "quiet")
 
+var exportAppCmd = withLocalFlags(&cobra.Command{
+	Use:   "export [<app>]",
+	Args:  cobra.MaximumNArgs(1),
+	Short: "Export the specified app from the engine to a qvf file",
+	Long: `Export the specified app, or the app given by --app, from the engine to a qvf file. The file is written to
+--out, or to the name of the app with the extension .qvf if not set. With --no-data the app is exported without
+data. The export fails, and no file is written, if fewer bytes than the size of the app are received.`,
+	Example: `corectl app export my-app.qvf --out backup/my-app.qvf
+corectl app export --app my-app.qvf --no-data --out my-app-without-data.qvf`,
+	Annotations: map[string]string{
+		"x-qlik-stability": "experimental",
+	},
+
+	Run: func(ccmd *cobra.Command, args []string) {
+		app := viper.GetString("app")
+		if len(args) > 0 {
+			app = args[0]
+		}
+		if app == "" {
+			log.Fatalln("no app specified")
+		}
+		path := ccmd.Flag("out").Value.String()
+		if path == "" {
+			path = strings.TrimSuffix(filepath.Base(app), ".qvf") + ".qvf"
+		}
+		size := internal.ExportApp(rootCtx, app, path, viper.GetBool("no-data"), headers, tlsClientConfig, viper.GetBool("json"))
+		log.Infof("Exported app (%s) to: ", internal.FormatBytes(int(size)))
+		log.Quietln(path)
+	},
+}, "out", "quiet")
+
+var copyAppCmd = withLocalFlags(&cobra.Command{
+	Use:   "copy <app> <name>",
+	Args:  cobra.ExactArgs(2),
+	Short: "Create a copy of the specified app with the given name, returns the ID of the created app",
+	Long: `Create a copy of the specified app with the given name, returns the ID of the created app. The script,
+connections, app properties, variables, dimensions, measures, objects and bookmarks are copied and the copy is
+then reloaded to load its data. With --no-data the copy is not reloaded and with --objects-only only the
+variables, dimensions, measures, objects and bookmarks are copied. Passwords of connections can not be read from
+the engine and are not copied. If the copy fails, the created app is deleted again.`,
+	Example: `corectl app copy sales.qvf sales-copy.qvf
+corectl app copy sales.qvf sales-template.qvf --objects-only`,
+	Annotations: map[string]string{
+		"x-qlik-stability": "experimental",
+	},
+
+	Run: func(ccmd *cobra.Command, args []string) {
+		options := &internal.CopyOptions{
+			NoData:      viper.GetBool("no-data"),
+			ObjectsOnly: viper.GetBool("objects-only"),
+		}
+		// Copy the headers before the session header of the app is added
+		targetHeaders := headers.Clone()
+		viper.Set("app", args[0])
+		state := internal.PrepareEngineState(rootCtx, headers, tlsClientConfig, false, false)
+		targetID := internal.CreateApp(rootCtx, state.Global, args[1])
+		var target *internal.State
+		log.OnFatal(func(message string) {
+			// The copy has to be closed before it can be deleted
+			if target != nil {
+				target.Global.DisconnectFromServer()
+			}
+			internal.DeleteIncompleteApp(state.Global, targetID)
+		})
+		// The copy is opened with data so that it can be saved
+		viper.Set("no-data", false)
+		target = internal.PrepareOtherEngineState(rootCtx, targetHeaders, tlsClientConfig, targetID, "", "")
+		internal.CopyApp(rootCtx, state.Doc, target.Doc, target.Global, options)
+		internal.SetAppIDToKnownApps(args[1], targetID, false)
+		log.Info("Copied app with new ID: ")
+		log.Quietln(targetID)
+	},
+}, "objects-only", "quiet")
+
+var renameAppCmd = &cobra.Command{
+	Use:   "rename <app> <title>",
+	Args:  cobra.ExactArgs(2),
+	Short: "Change the title of the specified app",
+	Long: `Change the title of the specified app and save it. The known apps are updated so that the app can be
+opened by its new title.`,
+	Example: "corectl app rename sales.qvf 'Sales 2024'",
+	Annotations: map[string]string{
+		"x-qlik-stability": "experimental",
+	},
+
+	Run: func(ccmd *cobra.Command, args []string) {
+		viper.Set("app", args[0])
+		state := internal.PrepareEngineState(rootCtx, headers, tlsClientConfig, false, false)
+		internal.RenameApp(rootCtx, state, args[1])
+	},
+}
+
+var execAppsCmd = withLocalFlags(&cobra.Command{
+	Use:   "exec [--filter <expression>]... -- <command> [<args>...]",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Run a corectl command against many apps",
+	Long: `Run a corectl command against each app in the engine that matches all filters. The command is run with
+--app set to the id of the app, along with the global flags given to exec such as --engine and --headers. The
+apps are processed in parallel, bounded by --parallelism, and a failing app does not stop the others. A summary
+of the results is printed when all apps are done, or a JSON report with the durations in seconds with --json or
+--out, and the exit code is non-zero if the command failed for any app.
+
+A filter consists of a field, an operator and a value:
+
+  name, id, title       = and != for equality, ~ for glob patterns and =~ for regular expressions
+  modified, reloaded    <, <=, > and >= with a date like 2024-01-31 or an age like 36h or 7d
+  size                  <, <=, > and >= with a size in bytes, optionally with the unit KB, MB or GB
+
+An age compares the age of the app, so 'reloaded<7d' matches apps that have been reloaded in the last seven
+days while 'reloaded>7d' matches apps that have not.`,
+	Example: `corectl app exec --filter 'name~sales-*' -- reload
+corectl app exec --filter 'id=~^[0-9a-f-]{36}$' --filter 'size>100MB' -- meta snapshot
+corectl app exec --parallelism 4 --out report.json -- unbuild --dir apps`,
+	Annotations: map[string]string{
+		"x-qlik-stability": "experimental",
+	},
+
+	Run: func(ccmd *cobra.Command, args []string) {
+		filters := parseAppFilters(ccmd)
+		state := internal.PrepareEngineState(rootCtx, headers, tlsClientConfig, false, true)
+		docList, err := state.Global.GetDocList(rootCtx)
+		if err != nil {
+			log.Fatalf("could not retrieve app list: %s\n", err)
+		}
+		apps := internal.FilterApps(docList, filters)
+		if len(apps) == 0 {
+			log.Fatalln("no apps match the filters")
+		}
+		log.Verbosef("Running the command against %d apps\n", len(apps))
+		results := internal.ExecInApps(rootCtx, apps, args, forwardedGlobalFlags(ccmd), viper.GetBool("json"))
+		if path := ccmd.Flag("out").Value.String(); path != "" {
+			internal.WriteAppExecReport(path, results)
+		}
+		printer.PrintAppExecResults(results)
+		for _, result := range results {
+			if !result.Success {
+				os.Exit(1)
+			}
+		}
+	},
+}, "filter", "out")
+
+// forwardedGlobalFlags returns the global flags given on the command line that are forwarded to each app
+func forwardedGlobalFlags(ccmd *cobra.Command) []string {
+	return forwardedFlags(ccmd.InheritedFlags())
+}
+
+// forwardedFlags returns the flags in the set that are given on the command line, in the form --name=value,
+// except the excluded flags and the ones that are set per app or would make the runs write to the same file
+func forwardedFlags(flagSet *pflag.FlagSet, excludedFlags ...string) []string {
+	excluded := map[string]bool{"app": true, "json": true, "bash": true, "record": true, "traffic-out": true}
+	for _, name := range excludedFlags {
+		excluded[name] = true
+	}
+	flags := []string{}
+	flagSet.VisitAll(func(flag *pflag.Flag) {
+		if !flag.Changed || excluded[flag.Name] {
+			return
+		}
+		switch value := flag.Value.(type) {
+		case pflag.SliceValue:
+			for _, item := range value.GetSlice() {
+				flags = append(flags, "--"+flag.Name+"="+item)
+			}
+		default:
+			if flag.Value.Type() == "stringToString" {
+				for key, item := range headersMap {
+					flags = append(flags, "--"+flag.Name+"="+key+"="+item)
+				}
+			} else {
+				flags = append(flags, "--"+flag.Name+"="+flag.Value.String())
+			}
+		}
+	})
+	return flags
+}
+
 var appCmd = &cobra.Command{
 	Use:   "app",
 	Short: "Explore and manage apps",
@@ -82,5 +316,5 @@ var appCmd = &cobra.Command{
 }
 
 func init() {
-	appCmd.AddCommand(listAppsCmd, removeAppCmd, importAppCmd)
+	appCmd.AddCommand(listAppsCmd, removeAppCmd, importAppCmd, exportAppCmd, copyAppCmd, renameAppCmd, execAppsCmd)
 }
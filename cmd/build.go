@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"os"
+	"strings"
+
 	"github.com/qlik-oss/corectl/internal"
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/qlik-oss/corectl/printer"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -10,30 +15,67 @@ var buildCmd = withLocalFlags(&cobra.Command{
 	Use:   "build",
 	Args:  cobra.ExactArgs(0),
 	Short: "Reload and save the app after updating connections, dimensions, measures, objects and the script",
+	Long: `Reload and save the app after updating connections, dimensions, measures, objects and the script
+
+The config file can list several apps that are built from the same project, each with its own properties such
+as the script and the object globs, while the top level properties such as connections, variables and headers
+are shared by all apps. The paths set by an app are relative to its root, if set, and otherwise to the config
+file:
+
+  connections: ./connections.yml
+  variables: ./common/variables/*.json
+  apps:
+    - app: sales.qvf
+      root: ./sales
+      script: ./script.qvs
+      objects: ./objects/*.json
+    - app: finance.qvf
+      script: ./finance/script.qvs
+
+Without --app all listed apps are built, or only the ones given by --only, one at a time or in parallel with
+--parallel. With --app only that app is built, using its properties from the list. The exit code is non-zero if
+any of the builds failed.`,
 	Example: `corectl build
-corectl build --connections ./myconnections.yml --script ./myscript.qvs`,
+corectl build --connections ./myconnections.yml --script ./myscript.qvs
+corectl build --report junit=build-report.xml
+corectl build --only sales.qvf,finance.qvf --parallel`,
 	Annotations: map[string]string{
 		"command_category": "build",
 	},
 
 	Run: func(ccmd *cobra.Command, args []string) {
+		if internal.HasProjectApps() && !ccmd.Flags().Changed("app") {
+			buildProjectApps(ccmd)
+			return
+		}
+		if ccmd.Flag("only").Changed {
+			log.Fatalln("--only can only be used with an apps list in the config file and without --app")
+		}
 		ctx := rootCtx
+		report := startBuildReport(ccmd.Flag("report").Value.String())
 		state := internal.PrepareEngineState(ctx, headers, tlsClientConfig, true, false)
+		internal.PreflightObjects(ctx, state.Doc, entityGlobPatterns(ccmd))
 
+		report.Phase("connections")
 		separateConnectionsFile := ccmd.Flag("connections").Value.String()
 		if separateConnectionsFile == "" {
 			separateConnectionsFile = getPathFlagFromConfigFile("connections")
 		}
 		internal.SetupConnections(ctx, state.Doc, separateConnectionsFile)
+		report.Phase("dimensions")
 		internal.SetDimensions(ctx, state.Doc, ccmd.Flag("dimensions").Value.String())
+		report.Phase("variables")
 		internal.SetVariables(ctx, state.Doc, ccmd.Flag("variables").Value.String())
+		report.Phase("measures")
 		internal.SetMeasures(ctx, state.Doc, ccmd.Flag("measures").Value.String())
+		report.Phase("objects")
 		internal.SetObjects(ctx, state.Doc, ccmd.Flag("objects").Value.String())
 		scriptFile := ccmd.Flag("script").Value.String()
 		if scriptFile == "" {
 			scriptFile = getPathFlagFromConfigFile("script")
 		}
 		if scriptFile != "" {
+			report.Phase("script")
 			internal.SetScript(ctx, state.Doc, scriptFile)
 		}
 
@@ -42,40 +84,193 @@ corectl build --connections ./myconnections.yml --script ./myscript.qvs`,
 			appProperties = getPathFlagFromConfigFile("app-properties")
 		}
 		if appProperties != "" {
+			report.Phase("app properties")
 			internal.SetAppProperties(ctx, state.Doc, appProperties)
 		}
 
 		if !viper.GetBool("no-reload") {
-			silent := viper.GetBool("silent")
-			limit := viper.GetInt("limit")
-			internal.Reload(ctx, state.Doc, state.Global, silent, limit)
+			report.Phase("reload")
+			options := getReloadOptions(ccmd)
+			internal.Reload(ctx, state.Doc, state.Global, options)
+			closeReloadEvents(options)
 		}
 
 		if !viper.GetBool("no-save") {
+			report.Phase("save")
 			internal.Save(ctx, state.Doc)
 		}
+		if report != nil {
+			cases := report.Finish()
+			format, path := parseReportFlag(ccmd.Flag("report").Value.String())
+			writeReport(format, path, viper.GetString("app"), cases)
+		}
 	},
-}, "script", "app-properties", "connections", "dimensions", "measures", "variables", "bookmarks", "objects", "no-reload", "silent", "no-save", "limit")
+}, "report", "script", "app-properties", "connections", "dimensions", "measures", "variables", "bookmarks", "objects", "no-reload", "silent", "no-save", "limit", "partial", "debug", "breakpoint", "events", "only", "parallel")
+
+// buildProjectApps builds the apps in the apps list of the config file, by running build with --app for each app
+func buildProjectApps(ccmd *cobra.Command) {
+	for _, name := range []string{"report", "events"} {
+		if ccmd.Flag(name).Changed {
+			log.Fatalf("--%s can not be used when building several apps, select one app with --app\n", name)
+		}
+	}
+	only, _ := ccmd.Flags().GetStringSlice("only")
+	parallel, _ := ccmd.Flags().GetBool("parallel")
+	apps := internal.SelectProjectApps(only)
+	log.Verbosef("Building %d apps\n", len(apps))
+	flags := forwardedFlags(ccmd.Flags(), "only", "parallel")
+	results := internal.BuildProjectApps(rootCtx, apps, flags, parallel, viper.GetBool("json"))
+	printer.PrintAppExecResults(results)
+	for _, result := range results {
+		if !result.Success {
+			os.Exit(1)
+		}
+	}
+}
+
+// startBuildReport starts recording the build if a report is requested. The report is also written if the
+// build fails.
+func startBuildReport(reportFlag string) *internal.BuildReport {
+	if reportFlag == "" {
+		return nil
+	}
+	format, path := parseReportFlag(reportFlag)
+	report := internal.StartBuildReport()
+	log.OnFatal(func(message string) {
+		report.Fail(message)
+		writeReport(format, path, viper.GetString("app"), report.Finish())
+	})
+	return report
+}
+
+// parseReportFlag splits a report flag like 'junit=report.xml' into the format and the path
+func parseReportFlag(reportFlag string) (format, path string) {
+	parts := strings.SplitN(reportFlag, "=", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		log.Fatalf("invalid report '%s', expected <format>=<path>, e.g. junit=report.xml\n", reportFlag)
+	}
+	if parts[0] != "junit" && parts[0] != "tap" {
+		log.Fatalf("unknown report format '%s', expected junit or tap\n", parts[0])
+	}
+	return parts[0], parts[1]
+}
 
 var reloadCmd = withLocalFlags(&cobra.Command{
-	Use:     "reload",
-	Args:    cobra.ExactArgs(0),
-	Short:   "Reload and save the app",
-	Long:    "Reload and save the app",
-	Example: "corectl reload",
+	Use:   "reload",
+	Args:  cobra.ExactArgs(0),
+	Short: "Reload and save the app",
+	Long: `Reload and save the app
+
+With --profile the time and number of rows of each table and statement reported in the reload progress are
+recorded and a summary of the slowest steps is printed. The profile can also be written as json, with the
+durations in seconds, with --out or in the Chrome trace event format with --trace, which can be opened in
+chrome://tracing or Perfetto.
+
+With --partial only the statements prefixed with ADD, REPLACE or MERGE are run and the data already in the app
+is kept. With --debug the reload runs in debug mode and stops at each --breakpoint line, where the tables loaded
+so far are printed before the reload continues.
+
+With --step the reload stops before each statement and shows the statement and the tables loaded so far. Press
+Enter to run the statement, 'c' to continue to the next breakpoint, 'b <line>' to run to a line or 'a' to abort
+the reload. It can not be combined with --quiet or --json.
+
+With --events the progress is written as JSON Lines to a file, or to stdout if the path is '-'. Each event has
+a type (reload-started, progress, error or reload-finished), a timestamp and, for progress, the statement, the
+table, the number of rows and whether the progress is persistent. In json mode the events are written to stdout,
+along with the messages as events of type log and the printed results, such as the profile, as events of type
+output.`,
+	Example: `corectl reload
+corectl reload --profile
+corectl reload --profile --out profile.json --trace trace.json
+corectl reload --partial
+corectl reload --debug --breakpoint 12 --breakpoint 40
+corectl reload --step --no-save
+corectl reload --events progress.jsonl`,
 	Annotations: map[string]string{
 		"command_category": "build",
 	},
 
 	Run: func(ccmd *cobra.Command, args []string) {
 		state := internal.PrepareEngineState(rootCtx, headers, tlsClientConfig, false, false)
-		silent := viper.GetBool("silent")
-		limit := viper.GetInt("limit")
+		outFile := ccmd.Flag("out").Value.String()
+		traceFile := ccmd.Flag("trace").Value.String()
 
-		internal.Reload(rootCtx, state.Doc, state.Global, silent, limit)
+		options := getReloadOptions(ccmd)
+		if viper.GetBool("profile") || outFile != "" || traceFile != "" {
+			options.Profile = internal.NewReloadProfile()
+		}
+		internal.Reload(rootCtx, state.Doc, state.Global, options)
+		closeReloadEvents(options)
+		if profile := options.Profile; profile != nil {
+			printer.PrintReloadProfile(profile)
+			if outFile != "" {
+				internal.WriteReloadProfile(outFile, profile)
+			}
+			if traceFile != "" {
+				internal.WriteReloadTrace(traceFile, profile)
+			}
+		}
 
 		if !viper.GetBool("no-save") {
 			internal.Save(rootCtx, state.Doc)
 		}
 	},
-}, "silent", "no-save", "limit")
+}, "silent", "no-save", "limit", "partial", "debug", "breakpoint", "step", "events", "profile", "out", "trace")
+
+// getReloadOptions reads the reload flags shared by build and reload. In json mode the progress is written
+// as events to stdout unless --events is set. Since stdout is then JSON Lines, messages and results in json mode
+// are written as events too. An events file is closed by closeReloadEvents, or when exiting
+// on an error.
+func getReloadOptions(ccmd *cobra.Command) *internal.ReloadOptions {
+	partial, _ := ccmd.Flags().GetBool("partial")
+	debug, _ := ccmd.Flags().GetBool("debug")
+	breakpoints, _ := ccmd.Flags().GetIntSlice("breakpoint")
+	step, _ := ccmd.Flags().GetBool("step")
+	options := &internal.ReloadOptions{
+		Silent:      viper.GetBool("silent"),
+		Limit:       viper.GetInt("limit"),
+		Partial:     partial,
+		Debug:       debug,
+		Breakpoints: breakpoints,
+		Step:        step,
+	}
+	eventsFile := ccmd.Flag("events").Value.String()
+	if eventsFile == "" && viper.GetBool("json") {
+		eventsFile = "-"
+	}
+	switch eventsFile {
+	case "":
+	case "-":
+		options.Events = internal.NewProgressEventWriter(os.Stdout)
+		options.Silent = true
+		if viper.GetBool("json") {
+			log.SetJSONLinesWriter(options.Events)
+		}
+	default:
+		events, err := internal.NewProgressEventFile(eventsFile)
+		if err != nil {
+			log.Fatalf("could not create events file '%s': %s\n", eventsFile, err)
+		}
+		options.Events = events
+		log.OnFatal(func(message string) {
+			if err := events.Close(); err != nil {
+				log.Errorf("could not write events file '%s': %s\n", eventsFile, err)
+			}
+		})
+	}
+	return options
+}
+
+// closeReloadEvents closes the events file after the reload and exits if the events could not be written
+func closeReloadEvents(options *internal.ReloadOptions) {
+	if options.Events == nil {
+		return
+	}
+	if err := options.Events.Close(); err != nil {
+		log.Fatalf("could not write reload events: %s\n", err)
+	}
+}
+
+func init() {
+	internal.AddValidProp("apps")
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/qlik-oss/corectl/internal"
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/qlik-oss/corectl/printer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var diffCmd = withLocalFlags(&cobra.Command{
+	Use:   "diff",
+	Args:  cobra.ExactArgs(0),
+	Short: "Compare the app with another app",
+	Long: `Compare the script, connections, variables, master items, sheets, objects and data model of the app with
+another app. The other app can be on another engine or be reached using another context, which makes it
+possible to compare the same app in two environments.
+
+Added entities are only in the other app and removed entities are only in the app. Changed entities and the
+script are printed as unified diffs of their properties.`,
+	Example: `corectl diff --app sales.qvf --other-app sales-copy.qvf
+corectl diff --app sales.qvf --other-context production
+corectl diff --app sales.qvf --other-engine other-host:9076 --json`,
+
+	Run: func(ccmd *cobra.Command, args []string) {
+		otherApp := viper.GetString("other-app")
+		otherEngine := viper.GetString("other-engine")
+		otherContext := viper.GetString("other-context")
+		if otherApp == "" && otherEngine == "" && otherContext == "" {
+			log.Fatalln("no other app specified, use --other-app, --other-engine or --other-context")
+		}
+		// Copy the headers before the session header of the first app is added
+		otherHeaders := headers.Clone()
+		state := internal.PrepareEngineState(rootCtx, headers, tlsClientConfig, false, false)
+		content := internal.ReadAppContent(rootCtx, state.Doc)
+		other := internal.PrepareOtherEngineState(rootCtx, otherHeaders, tlsClientConfig, otherApp, otherEngine, otherContext)
+		otherContent := internal.ReadAppContent(rootCtx, other.Doc)
+		printer.PrintAppDiff(internal.DiffApps(content, otherContent))
+	},
+}, "other-app", "other-engine", "other-context")
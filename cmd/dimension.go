@@ -28,6 +28,35 @@ var setDimensionsCmd = withLocalFlags(&cobra.Command{
 	},
 }, "no-save")
 
+var createDimensionCmd = withLocalFlags(&cobra.Command{
+	Use:   "create",
+	Args:  cobra.ExactArgs(0),
+	Short: "Create or update a master dimension in the current app",
+	Long: `Create or update a master dimension in the current app from the command line.
+Supplying more than one field creates a drill-down group. The fields are validated by the engine before the dimension is created.
+Use --append-to to also store the generated dimension in a project json file.`,
+	Example: `corectl dimension create --field Region
+corectl dimension create --id geo --label Geography --field Country --field City --append-to ./dimensions.json`,
+
+	Run: func(ccmd *cobra.Command, args []string) {
+		fields, _ := ccmd.Flags().GetStringSlice("field")
+		tags, _ := ccmd.Flags().GetStringSlice("tags")
+		state := internal.PrepareEngineState(rootCtx, headers, tlsClientConfig, false, false)
+		raw := internal.CreateDimension(rootCtx, state.Doc, internal.DimensionDef{
+			ID:     ccmd.Flag("id").Value.String(),
+			Label:  ccmd.Flag("label").Value.String(),
+			Fields: fields,
+			Tags:   tags,
+		})
+		if appendTo := ccmd.Flag("append-to").Value.String(); appendTo != "" {
+			internal.AppendDimensionToFile(appendTo, raw)
+		}
+		if !viper.GetBool("no-save") {
+			internal.Save(rootCtx, state.Doc)
+		}
+	},
+}, "id", "label", "field", "tags", "append-to", "no-save")
+
 var removeDimensionCmd = withLocalFlags(&cobra.Command{
 	Use:     "rm <dimension-id>...",
 	Args:    cobra.MinimumNArgs(1),
@@ -101,5 +130,5 @@ var dimensionCmd = &cobra.Command{
 }
 
 func init() {
-	dimensionCmd.AddCommand(listDimensionsCmd, setDimensionsCmd, getDimensionPropertiesCmd, getDimensionLayoutCmd, removeDimensionCmd)
+	dimensionCmd.AddCommand(listDimensionsCmd, setDimensionsCmd, createDimensionCmd, getDimensionPropertiesCmd, getDimensionLayoutCmd, removeDimensionCmd)
 }
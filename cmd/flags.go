@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"runtime"
+	"time"
 
 	"github.com/qlik-oss/corectl/internal"
 	"github.com/spf13/cobra"
@@ -17,13 +18,10 @@ var initialized bool
 var DefaultUnbuildFolder = "./<app name>-unbuild"
 
 // getPathFlagFromConfigFile returns a parameter from the config file.
-// It modifies the parameter to actually be relative to the config file and not the working directory
+// It modifies the parameter to actually be relative to the config file, or to the root of the app if it is set
+// by an app in the apps list, and not the working directory
 func getPathFlagFromConfigFile(paramName string) string {
-	pathInConfigFile := viper.GetString(paramName)
-	if pathInConfigFile != "" {
-		return internal.RelativeToProject(pathInConfigFile)
-	}
-	return ""
+	return internal.ConfigPath(paramName)
 }
 
 func withLocalFlags(ccmd *cobra.Command, localFlagNames ...string) *cobra.Command {
@@ -47,6 +45,11 @@ func initGlobalFlags(globalFlags *pflag.FlagSet) {
 	// bound to viper
 	globalFlags.BoolP("verbose", "v", false, "Log extra information")
 	globalFlags.BoolP("traffic", "t", false, "Log JSON websocket traffic to stdout")
+	globalFlags.StringSlice("traffic-methods", nil, "Only log traffic of requests to these methods, e.g. GetLayout,DoReload")
+	globalFlags.IntSlice("traffic-handles", nil, "Only log traffic of requests on these handles, -1 is the global handle")
+	globalFlags.Bool("traffic-pretty", false, "Pretty print the logged traffic")
+	globalFlags.Int("traffic-truncate", 0, "Truncate logged messages longer than this number of characters")
+	globalFlags.Bool("traffic-timing", false, "Log the time between each request and its response")
 	globalFlags.StringP("engine", "e", "localhost:9076", "URL to the Qlik Associative Engine")
 	globalFlags.StringP("app", "a", "", "Name or identifier of the app")
 	globalFlags.String("ttl", "0", "Qlik Associative Engine session time to live in seconds")
@@ -56,6 +59,10 @@ func initGlobalFlags(globalFlags *pflag.FlagSet) {
 	globalFlags.MarkHidden("bash")
 	globalFlags.String("context", "", "Name of the context used when connecting to Qlik Associative Engine")
 	globalFlags.Bool("insecure", false, "Enabling insecure will make it possible to connect using self signed certificates")
+	globalFlags.Int("parallelism", 10, "Maximum number of concurrent requests to the engine when fetching or setting entities")
+	globalFlags.Int("retries", 0, "Number of times to retry connecting to the engine and to re-attach to the session when the connection drops. Use together with --ttl so that the engine keeps the session")
+	globalFlags.Duration("retry-backoff", time.Second, "Time to wait before the first retry, it is doubled for every following retry")
+	globalFlags.Duration("timeout", 0, "Cancel the command if it has not finished within the duration, e.g. 30s or 10m. The exit code is 124 on timeout and 130 when interrupted")
 
 	globalFlags.VisitAll(func(flag *pflag.Flag) {
 		viper.BindPFlag(flag.Name, flag)
@@ -65,6 +72,8 @@ func initGlobalFlags(globalFlags *pflag.FlagSet) {
 	globalFlags.StringVarP(&explicitConfigFile, "config", "c", "", "path/to/config.yml where parameters can be set instead of on the command line")
 	globalFlags.StringToStringVar(&headersMap, "headers", nil, "Http headers to use when connecting to Qlik Associative Engine")
 	globalFlags.StringVar(&explicitCertificatePath, "certificates", "", "path/to/folder containing client.pem, client_key.pem and root.pem certificates")
+	globalFlags.StringVar(&trafficOutFile, "traffic-out", "", "path/to/traffic.log where traffic is logged instead of stdout, or stderr. Traffic is then logged also with --json")
+	globalFlags.StringVar(&trafficRecordFile, "record", "", "path/to/traffic.jsonl where all websocket traffic is recorded, it can be replayed with 'corectl replay'")
 
 	// Set annotation to run bash completion function
 	globalFlags.SetAnnotation("app", cobra.BashCompCustom, []string{"__corectl_get_apps"})
@@ -97,6 +106,15 @@ func initLocalFlags() {
 	localFlags.BoolP("quiet", "q", false, "Only print IDs. Useful for scripting")
 	localFlags.String("user", "", "Username to be used when logging in to Qlik Sense Enterprise")
 	localFlags.String("password", "", "Password to be used when logging in to Qlik Sense Enterprise (use with caution)")
+	localFlags.Bool("dot", false, "Print the result as a graph in Graphviz DOT format")
+	localFlags.Bool("sarif", false, "Print the findings in SARIF format")
+	localFlags.Bool("profile", false, "Record the time spent on each table and statement during the reload and print the slowest")
+	localFlags.Bool("junit", false, "Print the results as a JUnit XML report")
+	localFlags.String("other-app", "", "Name or identifier of the app to compare with, defaults to the app")
+	localFlags.String("other-engine", "", "URL to the engine of the app to compare with, defaults to the engine")
+	localFlags.Float64("max-row-drop", 10, "Maximum drop in the number of rows of a table or distinct values of a field, in percent")
+	localFlags.String("other-context", "", "Name of the context used to connect to the app to compare with")
+	localFlags.Bool("objects-only", false, "Only copy the variables, dimensions, measures, objects and bookmarks")
 
 	localFlags.VisitAll(func(flag *pflag.Flag) {
 		viper.BindPFlag(flag.Name, flag)
@@ -113,6 +131,15 @@ func initLocalFlags() {
 	localFlags.String("script", "", "Path to a qvs file containing the app data reload script")
 	localFlags.String("app-properties", "", "Path to a json file containing the app properties")
 	localFlags.String("dir", DefaultUnbuildFolder, "Path to a the folder where the unbuilt app is exported")
+	localFlags.String("fail-on", "", "Exit with a non-zero code if there are findings with this severity or higher (error, warning, info), or never with off")
+	localFlags.String("append-to", "", "Path to a json file that the created entity is appended to")
+	localFlags.String("out", "", "Path to the file the result is written to")
+	localFlags.String("events", "", "Path to a file the reload progress is written to as JSON Lines events, '-' for stdout")
+	localFlags.String("trace", "", "Path to a file the reload profile is written to in the Chrome trace event format")
+	localFlags.String("report", "", "Write a report of the build phases, e.g. junit=report.xml or tap=report.tap")
+
+	localFlags.SetAnnotation("other-app", cobra.BashCompCustom, []string{"__corectl_get_apps"})
+	localFlags.SetAnnotation("other-context", cobra.BashCompCustom, []string{"__corectl_get_contexts"})
 
 	if runtime.GOOS != "windows" {
 		// Set annotation to run bash completion function
@@ -125,10 +152,35 @@ func initLocalFlags() {
 		localFlags.SetAnnotation("measures", cobra.BashCompFilenameExt, []string{"json"})
 		localFlags.SetAnnotation("objects", cobra.BashCompFilenameExt, []string{"json"})
 		localFlags.SetAnnotation("script", cobra.BashCompFilenameExt, []string{"qvs"})
+		localFlags.SetAnnotation("append-to", cobra.BashCompFilenameExt, []string{"json"})
+		localFlags.SetAnnotation("out", cobra.BashCompFilenameExt, []string{"json"})
+		localFlags.SetAnnotation("trace", cobra.BashCompFilenameExt, []string{"json"})
+		localFlags.SetAnnotation("events", cobra.BashCompFilenameExt, []string{"jsonl"})
 	}
 
 	// Add all local flags to the set of valid config properties.
 	localFlags.VisitAll(func(flag *pflag.Flag) {
 		internal.AddValidProp(flag.Name)
 	})
+
+	// Neither bound to viper nor valid config properties. They only apply to a single run of a command, so setting
+	// them in the config file would silently change every run.
+	localFlags.String("id", "", "Identifier of the entity, generated from the label if omitted")
+	localFlags.String("label", "", "Label of the master item")
+	localFlags.String("expr", "", "Expression of the master measure")
+	localFlags.String("format", "", "Number format pattern of the master measure, e.g. '#,##0'")
+	localFlags.StringSlice("tags", nil, "Tags of the master item")
+	localFlags.StringSlice("field", nil, "Field (or calculated expression starting with '=') of the dimension, repeat to create a drill-down group")
+	localFlags.Bool("partial", false, "Run a partial reload where only statements prefixed with ADD, REPLACE or MERGE are run")
+	localFlags.Bool("debug", false, "Run the reload in debug mode and print the loaded tables at each breakpoint")
+	localFlags.IntSlice("breakpoint", nil, "Line in the script where a debug reload stops, repeat to add more breakpoints")
+	localFlags.Bool("step", false, "Step through the reload script interactively, one statement at a time")
+	localFlags.String("listen", "localhost:9076", "Address the replayed engine listens on")
+	localFlags.StringSlice("only", nil, "Only build these apps of the apps list in the config file, e.g. sales,finance")
+	localFlags.Bool("parallel", false, "Build the apps of the apps list in parallel, bounded by --parallelism")
+	localFlags.StringArray("filter", nil, "Only include apps matching the filter, e.g. 'name~sales-*', repeat to require more filters")
+	localFlags.String("sort", "", "Sort the apps by name, id, title, size, modified or reloaded")
+	localFlags.Bool("reverse", false, "Sort in reverse order, e.g. the largest or most recently reloaded apps first")
+	localFlags.StringSlice("columns", nil, "Columns to print, e.g. id,name,size,owner,meta.stream")
+	localFlags.String("output-format", "table", "Print the result as a table, csv or yaml")
 }
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/qlik-oss/corectl/internal"
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/qlik-oss/corectl/printer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var lintModelCmd = withLocalFlags(&cobra.Command{
+	Use:   "model",
+	Args:  cobra.ExactArgs(0),
+	Short: "Check the data model for common problems",
+	Long: `Check the data model for common problems and exit with a non-zero code if there are findings with the
+--fail-on severity or higher.
+
+The following rules are checked:
+  synthetic-keys       (error)   synthetic key tables
+  circular-references  (error)   tables loosely coupled because of circular references
+  low-key-overlap      (warning) key fields with few values present in both linked tables
+  high-cardinality     (info)    non-key fields with nearly unique values in large tables
+  unassociated-tables  (warning) tables not associated with any other table
+  timestamps-as-text   (warning) dates and timestamps stored as text
+
+The severity of each rule, including 'off', and the thresholds can be set in the config file:
+  lint:
+    model:
+      fail-on: warning
+      min-key-overlap: 0.5
+      max-cardinality-ratio: 0.9
+      min-rows: 1000
+      rules:
+        high-cardinality: off`,
+	Example: `corectl lint model
+corectl lint model --fail-on warning --json`,
+
+	Run: func(ccmd *cobra.Command, args []string) {
+		failOn := lintFailOn(ccmd, "model")
+		state := internal.PrepareEngineState(rootCtx, headers, tlsClientConfig, false, false)
+		engine := internal.GetEngineURL()
+		data := internal.GetModelMetadata(rootCtx, state.Doc, state.AppID, engine, headers, tlsClientConfig, false)
+		findings := internal.LintModel(data)
+		printer.PrintLintFindings(findings)
+		if internal.LintFailed(findings, failOn) {
+			os.Exit(1)
+		}
+	},
+}, "fail-on")
+
+var lintScriptCmd = withLocalFlags(&cobra.Command{
+	Use:   "script [<path-to-script-file.qvs>]",
+	Args:  cobra.MaximumNArgs(1),
+	Short: "Check the load script for common problems",
+	Long: `Check the load script for common problems without connecting to the engine and exit with a non-zero
+code if there are findings with the --fail-on severity or higher. If no script file is given the
+script in the config file is used.
+
+The following rules are checked:
+  load-star           (warning) LOAD * or SELECT * from a file or connection
+  missing-drop        (warning) temporary tables that are never dropped
+  auto-concatenate    (warning) tables with the same fields as an earlier table and no Concatenate or NoConcatenate
+  unknown-connection  (error)   lib:// paths not matching any connection in the connections config
+  set-let-misuse      (warning) SET of values that need evaluation and LET of aggregation expressions
+
+Temporary tables are the ones with names matching the temp-table-pattern, by default '^(?i)(tmp|temp)'.
+The severity of each rule, including 'off', can be set in the config file:
+  lint:
+    script:
+      fail-on: warning
+      temp-table-pattern: ^_
+      rules:
+        load-star: off`,
+	Example: `corectl lint script
+corectl lint script ./my-script-file.qvs --connections ./connections.yml
+corectl lint script --sarif > script.sarif`,
+
+	Run: func(ccmd *cobra.Command, args []string) {
+		failOn := lintFailOn(ccmd, "script")
+		scriptFile := ""
+		if len(args) > 0 {
+			scriptFile = args[0]
+		} else {
+			scriptFile = getPathFlagFromConfigFile("script")
+		}
+		if scriptFile == "" {
+			log.Fatalln("no loadscript (.qvs) file specified.")
+		}
+		var connections *internal.ConnectionsConfig
+		if connectionsFile := ccmd.Flag("connections").Value.String(); connectionsFile != "" {
+			connections = internal.ReadConnectionsFile(connectionsFile)
+		} else if internal.ConfigDir != "" {
+			connections = internal.GetConnectionsConfig()
+		}
+		findings := internal.LintScript(scriptFile, connections)
+		if viper.GetBool("sarif") {
+			printer.PrintLintFindingsAsSarif(findings)
+		} else {
+			printer.PrintLintFindings(findings)
+		}
+		if internal.LintFailed(findings, failOn) {
+			os.Exit(1)
+		}
+	},
+}, "fail-on", "connections", "sarif")
+
+var lintObjectsCmd = withLocalFlags(&cobra.Command{
+	Use:   "objects",
+	Args:  cobra.ExactArgs(0),
+	Short: "Validate the object, measure, dimension, variable and bookmark files",
+	Long: `Validate the object, measure, dimension, variable and bookmark files without connecting to the engine
+and exit with a non-zero code if there are findings with the --fail-on severity or higher. The files are
+found using the glob patterns given as flags or in the config file. Each problem is reported with the file
+and the JSON pointer of the invalid value. The same validation is run by 'corectl build' before the app is updated, where
+master items already in the app are also valid qLibraryId references and findings with severity error stop the build.
+
+The following rules are checked:
+  schema              (error)   properties not matching the bundled JSON schema of the qType or visualization
+  unknown-library-id  (error)   qLibraryId references to master measures or dimensions not in any of the files
+  duplicate-id        (error)   qIds used by more than one entity
+
+The severity of each rule, including 'off', can be set in the config file:
+  lint:
+    objects:
+      fail-on: warning
+      rules:
+        unknown-library-id: warning`,
+	Example: `corectl lint objects
+corectl lint objects --objects "./objects/*.json" --measures ./measures.json`,
+
+	Run: func(ccmd *cobra.Command, args []string) {
+		failOn := lintFailOn(ccmd, "objects")
+		findings := internal.LintObjects(entityGlobPatterns(ccmd), nil)
+		if viper.GetBool("sarif") {
+			printer.PrintLintFindingsAsSarif(findings)
+		} else {
+			printer.PrintLintFindings(findings)
+		}
+		if internal.LintFailed(findings, failOn) {
+			os.Exit(1)
+		}
+	},
+}, "fail-on", "sarif", "objects", "measures", "dimensions", "variables", "bookmarks")
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check the app for common problems",
+	Long:  "Check the app for common problems",
+}
+
+// lintFailOn returns the --fail-on severity, falling back on the config of the linter
+func lintFailOn(ccmd *cobra.Command, linter string) string {
+	failOn := ccmd.Flag("fail-on").Value.String()
+	if failOn == "" {
+		failOn = internal.LintFailOn(linter)
+	}
+	if err := internal.ValidateSeverity(failOn); err != nil {
+		log.Fatalln(err)
+	}
+	return failOn
+}
+
+// entityGlobPatterns returns the glob pattern flag of each kind of entity file
+func entityGlobPatterns(ccmd *cobra.Command) map[string]string {
+	patterns := map[string]string{}
+	for _, kind := range []string{"objects", "measures", "dimensions", "variables", "bookmarks"} {
+		patterns[kind] = ccmd.Flag(kind).Value.String()
+	}
+	return patterns
+}
+
+func init() {
+	lintCmd.AddCommand(lintModelCmd, lintObjectsCmd, lintScriptCmd)
+	internal.AddValidProp("lint")
+}
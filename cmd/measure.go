@@ -28,6 +28,35 @@ var setMeasuresCmd = withLocalFlags(&cobra.Command{
 	},
 }, "no-save")
 
+var createMeasureCmd = withLocalFlags(&cobra.Command{
+	Use:   "create",
+	Args:  cobra.ExactArgs(0),
+	Short: "Create or update a master measure in the current app",
+	Long: `Create or update a master measure in the current app from the command line.
+The expression is validated by the engine before the measure is created.
+Use --append-to to also store the generated measure in a project json file.`,
+	Example: `corectl measure create --id rev --label Revenue --expr "Sum(Sales)" --format '#,##0' --tags finance
+corectl measure create --label Revenue --expr "Sum(Sales)" --append-to ./measures.json`,
+
+	Run: func(ccmd *cobra.Command, args []string) {
+		tags, _ := ccmd.Flags().GetStringSlice("tags")
+		state := internal.PrepareEngineState(rootCtx, headers, tlsClientConfig, false, false)
+		raw := internal.CreateMeasure(rootCtx, state.Doc, internal.MeasureDef{
+			ID:         ccmd.Flag("id").Value.String(),
+			Label:      ccmd.Flag("label").Value.String(),
+			Expression: ccmd.Flag("expr").Value.String(),
+			Format:     ccmd.Flag("format").Value.String(),
+			Tags:       tags,
+		})
+		if appendTo := ccmd.Flag("append-to").Value.String(); appendTo != "" {
+			internal.AppendMeasureToFile(appendTo, raw)
+		}
+		if !viper.GetBool("no-save") {
+			internal.Save(rootCtx, state.Doc)
+		}
+	},
+}, "id", "label", "expr", "format", "tags", "append-to", "no-save")
+
 var removeMeasureCmd = withLocalFlags(&cobra.Command{
 	Use:     "rm <measure-id>...",
 	Args:    cobra.MinimumNArgs(1),
@@ -101,5 +130,5 @@ var measureCmd = &cobra.Command{
 }
 
 func init() {
-	measureCmd.AddCommand(listMeasuresCmd, setMeasuresCmd, getMeasurePropertiesCmd, getMeasureLayoutCmd, removeMeasureCmd)
+	measureCmd.AddCommand(listMeasuresCmd, setMeasuresCmd, createMeasureCmd, getMeasurePropertiesCmd, getMeasureLayoutCmd, removeMeasureCmd)
 }
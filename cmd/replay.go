@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/qlik-oss/corectl/internal/replay"
+	"github.com/spf13/cobra"
+)
+
+var replayCmd = withLocalFlags(&cobra.Command{
+	Use:   "replay <traffic.jsonl>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Serve recorded websocket traffic as an engine",
+	Long: `Serve websocket traffic recorded with --record back to corectl, acting as a Qlik Associative Engine.
+Each connection replays one recorded session, and requests are answered with the recorded response to the same
+method, handle and parameters. This makes it possible to rerun commands without a live engine. The server runs
+until it is interrupted.`,
+	Example: `corectl build --record traffic.jsonl
+corectl replay traffic.jsonl
+corectl build --engine localhost:9076
+
+corectl replay traffic.jsonl --listen localhost:9999`,
+	Annotations: map[string]string{
+		"command_category": "other",
+	},
+
+	Run: func(ccmd *cobra.Command, args []string) {
+		records, err := replay.ReadRecording(args[0])
+		if err != nil {
+			log.Fatalf("could not read traffic recording '%s': %s\n", args[0], err)
+		}
+		server := replay.NewServer(records)
+		address := ccmd.Flag("listen").Value.String()
+		if err := server.Listen(address); err != nil {
+			log.Fatalf("could not listen on '%s': %s\n", address, err)
+		}
+		defer server.Close()
+		log.Infof("Replaying %d recorded session(s) on %s\n", server.Sessions(), server.URL)
+		<-rootCtx.Done()
+	},
+}, "listen")
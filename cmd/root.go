@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/qlik-oss/corectl/internal"
+	"github.com/qlik-oss/corectl/internal/log"
 	"github.com/qlik-oss/corectl/printer"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -18,6 +19,8 @@ import (
 var headersMap = make(map[string]string)
 var explicitConfigFile = ""
 var explicitCertificatePath = ""
+var trafficRecordFile = ""
+var trafficOutFile = ""
 var version = ""
 var commit = ""
 var branch = ""
@@ -69,6 +72,19 @@ var rootCmd = &cobra.Command{
 
 		// Initiate the printers mode
 		printer.Init()
+
+		if trafficOutFile != "" {
+			log.SetTrafficOutput(trafficOutFile)
+		}
+		if trafficRecordFile != "" {
+			log.RecordTraffic(trafficRecordFile)
+		}
+
+		// Engine calls are cancelled when the timeout expires or the process is interrupted
+		rootCtx = internal.NewCancellableContext(viper.GetDuration("timeout"))
+		log.ExitCode = func() int {
+			return internal.ExitCode(rootCtx)
+		}
 	},
 
 	Run: func(ccmd *cobra.Command, args []string) {
@@ -157,11 +173,16 @@ func init() {
 	rootCmd.AddCommand(getKeysCmd)
 	rootCmd.AddCommand(buildCmd)
 	rootCmd.AddCommand(evalCmd)
+	rootCmd.AddCommand(testCmd)
 	rootCmd.AddCommand(reloadCmd)
 	rootCmd.AddCommand(getValuesCmd)
 	rootCmd.AddCommand(getMetaCmd)
 	rootCmd.AddCommand(contextCmd)
 	rootCmd.AddCommand(unbuildCmd)
+	rootCmd.AddCommand(lineageCmd)
+	rootCmd.AddCommand(analyzeCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(diffCmd)
 
 	// Subcommands
 	rootCmd.AddCommand(alternateStateCmd)
@@ -176,6 +197,7 @@ func init() {
 
 	// Other
 	rootCmd.AddCommand(catwalkCmd)
+	rootCmd.AddCommand(replayCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(completionCmd)
 	rootCmd.AddCommand(statusCmd)
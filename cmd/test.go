@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/qlik-oss/corectl/internal"
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/qlik-oss/corectl/printer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var testCmd = withLocalFlags(&cobra.Command{
+	Use:   "test [<file.test.yml>...]",
+	Short: "Run data assertions against the app",
+	Long: `Evaluate the expressions declared in the tests section of the config file, or in the given test files,
+and compare the results with the expected values. Each test can select values in fields before the expression
+is evaluated, selections are cleared between tests. The results are printed in the Test Anything Protocol (TAP)
+format or as a JUnit XML report, and the command exits with a non-zero code if any test fails.
+
+A test file contains a list of tests under the key 'tests':
+
+tests:
+  - name: EU sales
+    expression: Sum(Sales)
+    selections:
+      Region: EU
+    equals: 1234
+    tolerance: 0.5
+  - expression: Count(distinct Customer)
+    selections:
+      Country: [Sweden, Norway]
+    min: 10
+    max: 100
+  - expression: Only(Currency)
+    equals: EUR`,
+	Example: `corectl test
+corectl test sales.test.yml
+corectl test 'tests/*.test.yml' --junit --out report.xml`,
+	Annotations: map[string]string{
+		"command_category": "build",
+	},
+
+	Run: func(ccmd *cobra.Command, args []string) {
+		tests := internal.ReadDataTests(args)
+		state := internal.PrepareEngineState(rootCtx, headers, tlsClientConfig, false, false)
+		cases := internal.RunDataTests(rootCtx, state.Doc, tests)
+
+		if viper.GetBool("json") {
+			log.PrintAsJSON(cases)
+		} else {
+			format := "tap"
+			if viper.GetBool("junit") {
+				format = "junit"
+			}
+			writeReport(format, ccmd.Flag("out").Value.String(), state.AppName, cases)
+		}
+		for _, testCase := range cases {
+			if !testCase.Passed() {
+				os.Exit(1)
+			}
+		}
+	},
+}, "junit", "out")
+
+// writeReport writes the test cases as TAP or JUnit XML to the file, or to stdout if path is empty
+func writeReport(format, path, suiteName string, cases []*internal.TestCase) {
+	var out io.Writer = os.Stdout
+	if path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			log.Fatalf("could not create report file '%s': %s\n", path, err)
+		}
+		defer file.Close()
+		out = file
+	}
+	switch format {
+	case "junit":
+		printer.WriteJUnit(out, suiteName, cases)
+	case "tap":
+		printer.WriteTAP(out, cases)
+	default:
+		log.Fatalf("unknown report format '%s', expected junit or tap\n", format)
+	}
+}
+
+func init() {
+	internal.AddValidProp("tests")
+}
@@ -9,7 +9,10 @@ import (
 
 // ListAlternateStates will return a list of all alternate states in an app.
 func ListAlternateStates(ctx context.Context, doc *enigma.Doc) []string {
-	appLayout, _ := doc.GetAppLayout(ctx)
+	appLayout, err := doc.GetAppLayout(ctx)
+	if err != nil {
+		log.Fatalf("could not list alternate states: %s\n", err)
+	}
 	return appLayout.StateNames
 }
 
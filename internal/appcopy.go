@@ -0,0 +1,171 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/qlik-oss/enigma-go"
+	"github.com/spf13/viper"
+)
+
+// CopyOptions tells what CopyApp copies
+type CopyOptions struct {
+	// NoData skips the reload of the copy, which then has the script but no data
+	NoData bool
+	// ObjectsOnly only copies the variables, dimensions, measures, objects and bookmarks
+	ObjectsOnly bool
+}
+
+// CreateApp creates an app with the given name and returns its id. The app is not added to the known apps, which
+// is left to the caller once the app is complete.
+func CreateApp(ctx context.Context, global *enigma.Global, appName string) string {
+	success, appID, err := global.CreateApp(ctx, appName, "")
+	if err != nil {
+		log.Fatalf("could not create app with name '%s': %s\n", appName, err)
+	}
+	if !success {
+		log.Fatalf("could not create app with name '%s'\n", appName)
+	}
+	return appID
+}
+
+// DeleteIncompleteApp deletes an app created by CreateApp that could not be completed. Since it is called when
+// exiting on an error, possibly after the command was cancelled, it does not use the context of the command and
+// failing to delete the app is only logged.
+func DeleteIncompleteApp(global *enigma.Global, appID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+	defer cancel()
+	if success, err := global.DeleteApp(ctx, appID); err != nil || !success {
+		log.Warnf("could not delete the incomplete app %s, it has to be deleted manually\n", appID)
+		return
+	}
+	log.Infoln("Deleted the incomplete app " + appID)
+}
+
+// CopyApp copies the script, connections, app properties, variables, dimensions, measures, objects and bookmarks
+// of the app to the target app and saves it. The data is not copied, instead the target is reloaded unless NoData or
+// ObjectsOnly is set. Passwords of connections can not be read from the engine and are not copied.
+func CopyApp(ctx context.Context, doc *enigma.Doc, target *enigma.Doc, targetGlobal *enigma.Global, options *CopyOptions) {
+	if !options.ObjectsOnly {
+		copyScript(ctx, doc, target)
+		copyConnections(ctx, doc, target)
+		copyAppProperties(ctx, doc, target)
+	}
+	copyEntities(ctx, "variables", collectVariables(ctx, doc, ""), func(raw json.RawMessage) error {
+		variable := &Variable{}
+		json.Unmarshal(raw, variable)
+		return setVariable(ctx, target, variable.Name, raw)
+	})
+	measures, dimensions, objects := collectEntities(ctx, doc, "")
+	copyEntities(ctx, "dimensions", dimensions, func(raw json.RawMessage) error {
+		dimension := &Dimension{}
+		json.Unmarshal(raw, dimension)
+		return setDimension(ctx, target, dimension.Info.Id, raw)
+	})
+	copyEntities(ctx, "measures", measures, func(raw json.RawMessage) error {
+		measure := &Measure{}
+		json.Unmarshal(raw, measure)
+		return setMeasure(ctx, target, measure.Info.Id, raw)
+	})
+	copyEntities(ctx, "objects", objects, func(raw json.RawMessage) error {
+		object := &Object{}
+		json.Unmarshal(raw, object)
+		return setObject(ctx, target, object.Info, object.Properties, raw)
+	})
+	copyEntities(ctx, "bookmarks", collectBookmarks(ctx, doc), func(raw json.RawMessage) error {
+		bookmark := &Bookmark{}
+		json.Unmarshal(raw, bookmark)
+		return setBookmark(ctx, target, bookmark.Info.Id, raw)
+	})
+	if !options.NoData && !options.ObjectsOnly {
+		Reload(ctx, target, targetGlobal, &ReloadOptions{})
+	}
+	log.Infoln("Saving app...")
+	if err := target.DoSave(ctx, ""); err != nil {
+		log.Fatalf("could not save the copy: %s\n", err)
+	}
+}
+
+func copyScript(ctx context.Context, doc *enigma.Doc, target *enigma.Doc) {
+	script, err := doc.GetScript(ctx)
+	if err != nil {
+		log.Fatalf("could not retrieve script: %s\n", err)
+	}
+	if err = target.SetScript(ctx, script); err != nil {
+		log.Fatalf("could not set script: %s\n", err)
+	}
+}
+
+func copyConnections(ctx context.Context, doc *enigma.Doc, target *enigma.Doc) {
+	connections, err := doc.GetConnections(ctx)
+	if err != nil {
+		log.Fatalf("could not retrieve connections: %s\n", err)
+	}
+	for _, connection := range connections {
+		copied := &enigma.Connection{
+			Name:             connection.Name,
+			Type:             connection.Type,
+			ConnectionString: connection.ConnectionString,
+			UserName:         connection.UserName,
+		}
+		if _, err := target.CreateConnection(ctx, copied); err != nil {
+			log.Fatalf("could not create connection %s: %s\n", connection.Name, err)
+		}
+		if connection.UserName != "" {
+			log.Warnf("The password of connection %s is not copied\n", connection.Name)
+		}
+	}
+}
+
+func copyAppProperties(ctx context.Context, doc *enigma.Doc, target *enigma.Doc) {
+	properties, err := doc.GetAppProperties(ctx)
+	if err != nil {
+		log.Fatalf("could not retrieve app properties: %s\n", err)
+	}
+	targetProperties, err := target.GetAppProperties(ctx)
+	if err != nil {
+		log.Fatalf("could not retrieve app properties: %s\n", err)
+	}
+	// The copy keeps its own title
+	properties.Title = targetProperties.Title
+	if err = target.SetAppProperties(ctx, properties); err != nil {
+		log.Fatalf("could not set app properties: %s\n", err)
+	}
+}
+
+// copyEntities sets the entities in the target app with bounded parallelism and exits if any of them fails
+func copyEntities(ctx context.Context, kind string, entities []JSONWithOrder, set func(raw json.RawMessage) error) {
+	errs := forEach(len(entities), "", func(i int) error {
+		return set(entities[i].JSON)
+	})
+	failOnErrors(errs, fmt.Sprintf("could not copy all %s", kind))
+	log.Verbosef("Copied %d %s\n", len(entities), kind)
+}
+
+// RenameApp sets the title of the app and saves it. The new title is added to the known apps so that the app
+// can be opened by it, and the previous name is removed from them if it was known.
+func RenameApp(ctx context.Context, state *State, title string) {
+	properties, err := state.Doc.GetAppProperties(ctx)
+	if err != nil {
+		log.Fatalf("could not retrieve app properties: %s\n", err)
+	}
+	properties.Title = title
+	if err = state.Doc.SetAppProperties(ctx, properties); err != nil {
+		log.Fatalf("could not set app properties: %s\n", err)
+	}
+	// An app opened without data can only save its objects
+	if viper.GetBool("no-data") {
+		err = state.Doc.SaveObjects(ctx)
+	} else {
+		err = state.Doc.DoSave(ctx, "")
+	}
+	if err != nil {
+		log.Fatalf("could not save the app: %s\n", err)
+	}
+	if _, known := applyNameToIDTransformation(state.AppName); known && state.AppName != title {
+		SetAppIDToKnownApps(state.AppName, state.AppID, true)
+	}
+	SetAppIDToKnownApps(title, state.AppID, false)
+}
@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qlik-oss/corectl/test/fakeengine"
+	"github.com/qlik-oss/enigma-go"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func openFakeApp(t *testing.T, engine *fakeengine.Engine, appID string) (*enigma.Global, *enigma.Doc) {
+	global, err := enigma.Dialer{}.Dial(context.Background(), engine.URL, nil)
+	assert.Nil(t, err)
+	doc, err := global.OpenDoc(context.Background(), appID, "", "", "", false)
+	assert.Nil(t, err)
+	return global, doc
+}
+
+func TestCopyApp(t *testing.T) {
+	ctx := context.Background()
+	engine := fakeengine.New()
+	assert.Nil(t, engine.Start())
+	defer engine.Close()
+	app := engine.AddApp("sales")
+	app.Script = "LOAD 1 as a AutoGenerate 1;"
+
+	global, doc := openFakeApp(t, engine, "sales")
+	defer global.DisconnectFromServer()
+	_, err := doc.CreateConnection(ctx, &enigma.Connection{Name: "data", Type: "folder", ConnectionString: "/data"})
+	assert.Nil(t, err)
+	_, err = doc.CreateMeasureRaw(ctx, []byte(`{"qInfo":{"qId":"m1","qType":"measure"},"qMeasure":{"qDef":"Sum(a)"},"qMetaDef":{"title":"A"}}`))
+	assert.Nil(t, err)
+	_, err = doc.CreateObjectRaw(ctx, []byte(`{"qInfo":{"qId":"o1","qType":"chart"}}`))
+	assert.Nil(t, err)
+	_, err = doc.CreateBookmarkRaw(ctx, []byte(`{"qInfo":{"qId":"b1","qType":"bookmark"},"qMetaDef":{"title":"North"}}`))
+	assert.Nil(t, err)
+
+	for _, options := range []*CopyOptions{{NoData: true}, {ObjectsOnly: true}} {
+		targetApp := engine.AddApp("copy")
+		targetGlobal, target := openFakeApp(t, engine, "copy")
+		CopyApp(ctx, doc, target, targetGlobal, options)
+		targetGlobal.DisconnectFromServer()
+
+		assert.Contains(t, targetApp.Entities, "m1")
+		assert.Contains(t, targetApp.Entities, "o1")
+		assert.Contains(t, targetApp.Entities, "b1")
+		assert.Equal(t, 1, targetApp.Saved)
+		assert.Equal(t, 0, targetApp.Reloaded)
+		if options.ObjectsOnly {
+			assert.Empty(t, targetApp.Script)
+			assert.Empty(t, targetApp.Connections)
+		} else {
+			assert.Equal(t, app.Script, targetApp.Script)
+			assert.Len(t, targetApp.Connections, 1)
+		}
+	}
+}
+
+func TestDeleteIncompleteApp(t *testing.T) {
+	ctx := context.Background()
+	engine := fakeengine.New()
+	assert.Nil(t, engine.Start())
+	defer engine.Close()
+	global, err := enigma.Dialer{}.Dial(ctx, engine.URL, nil)
+	assert.Nil(t, err)
+	defer global.DisconnectFromServer()
+
+	appID := CreateApp(ctx, global, "copy")
+	assert.Contains(t, engine.Apps, appID)
+	DeleteIncompleteApp(global, appID)
+	assert.NotContains(t, engine.Apps, appID)
+}
+
+func TestRenameApp(t *testing.T) {
+	ctx := context.Background()
+	engine := fakeengine.New()
+	assert.Nil(t, engine.Start())
+	defer engine.Close()
+	engine.AddApp("app-id")
+	viper.Set("engine", engine.URL)
+	defer viper.Set("engine", nil)
+
+	dir, err := ioutil.TempDir("", "corectl")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	previousPath := knownAppsFilePath
+	knownAppsFilePath = filepath.Join(dir, "knownApps.yml")
+	defer func() { knownAppsFilePath = previousPath }()
+	SetAppIDToKnownApps("sales", "app-id", false)
+
+	global, doc := openFakeApp(t, engine, "app-id")
+	defer global.DisconnectFromServer()
+	RenameApp(ctx, &State{Doc: doc, Global: global, AppName: "sales", AppID: "app-id"}, "Sales 2024")
+
+	properties, err := doc.GetAppProperties(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "Sales 2024", properties.Title)
+	appID, known := applyNameToIDTransformation("Sales 2024")
+	assert.True(t, known)
+	assert.Equal(t, "app-id", appID)
+	_, known = applyNameToIDTransformation("sales")
+	assert.False(t, known)
+}
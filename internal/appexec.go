@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/qlik-oss/enigma-go"
+)
+
+// AppExecResult is the outcome of running a corectl command against one app. The duration is written to JSON in
+// seconds.
+type AppExecResult struct {
+	AppID    string        `json:"appId"`
+	AppName  string        `json:"appName"`
+	Success  bool          `json:"success"`
+	ExitCode int           `json:"exitCode"`
+	Duration time.Duration `json:"duration"`
+	Output   string        `json:"output"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// MarshalJSON writes the duration in seconds
+func (r AppExecResult) MarshalJSON() ([]byte, error) {
+	type result AppExecResult
+	return json.Marshal(&struct {
+		result
+		Duration float64 `json:"duration"`
+	}{result(r), r.Duration.Seconds()})
+}
+
+// ExecInApps runs corectl with the arguments once per app, with the app set by --app and the flags appended. The
+// apps are processed with bounded parallelism and a failure does not stop the other apps. The output of each run
+// is printed when it finishes unless quiet is set.
+func ExecInApps(ctx context.Context, apps []*enigma.DocListEntry, args []string, flags []string, quiet bool) []*AppExecResult {
+	executable := corectlExecutable()
+	results := make([]*AppExecResult, len(apps))
+	forEach(len(apps), "Finished apps", func(i int) error {
+		app := apps[i]
+		commandArgs := withAppFlags(args, append([]string{"--app=" + app.DocId}, flags...))
+		result := runCorectl(ctx, executable, commandArgs, nil)
+		result.AppID = app.DocId
+		result.AppName = app.DocName
+		if !quiet {
+			log.Infof("==> %s (%s) <==\n%s\n", app.DocName, app.DocId, result.Output)
+		}
+		results[i] = result
+		return nil
+	})
+	return results
+}
+
+// corectlExecutable returns the path of the running corectl executable
+func corectlExecutable() string {
+	executable, err := os.Executable()
+	if err != nil {
+		log.Fatalln("could not find the corectl executable:", err)
+	}
+	return executable
+}
+
+// runCorectl runs corectl with the arguments and collects the output. The output is also written to stream,
+// if set, while the command runs.
+func runCorectl(ctx context.Context, executable string, args []string, stream io.Writer) *AppExecResult {
+	result := &AppExecResult{}
+	command := exec.CommandContext(ctx, executable, args...)
+	output := &bytes.Buffer{}
+	var writer io.Writer = output
+	if stream != nil {
+		writer = io.MultiWriter(output, stream)
+	}
+	command.Stdout = writer
+	command.Stderr = writer
+	start := time.Now()
+	err := command.Run()
+	result.Duration = time.Since(start)
+	result.Output = output.String()
+	result.Success = err == nil
+	if err != nil {
+		result.Error = err.Error()
+		result.ExitCode = -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			result.ExitCode = exitErr.ExitCode()
+		}
+	}
+	return result
+}
+
+// WriteAppExecReport saves the results as json
+func WriteAppExecReport(path string, results []*AppExecResult) {
+	if err := ioutil.WriteFile(path, marshalOrFail(results), 0644); err != nil {
+		log.Fatalf("could not write report file '%s': %s\n", path, err)
+	}
+	log.Verboseln("Saved report to " + path)
+}
+
+// withAppFlags adds the flags to the arguments, before any '--' that ends the flags of the command
+func withAppFlags(args, flags []string) []string {
+	for i, arg := range args {
+		if arg == "--" {
+			return append(append(append([]string{}, args[:i]...), flags...), args[i:]...)
+		}
+	}
+	return append(append([]string{}, args...), flags...)
+}
@@ -0,0 +1,16 @@
+package internal
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppExecResultJSON(t *testing.T) {
+	result := &AppExecResult{AppID: "app-id", AppName: "sales", Success: true, Duration: 2500 * time.Millisecond, Output: "ok"}
+	encoded, err := json.Marshal(result)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"appId": "app-id", "appName": "sales", "success": true, "exitCode": 0, "duration": 2.5, "output": "ok"}`, string(encoded))
+}
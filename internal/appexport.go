@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"os"
+
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/qlik-oss/corectl/internal/rest"
+)
+
+// Progress of exports of unknown size is logged for every exportProgressStep bytes
+const exportProgressStep = 10 << 20
+
+// ExportApp downloads the app from the engine to the file at path and returns the size of the file. The app is
+// first written to path with the extension '.part', which is renamed when the whole app has been received, so that
+// a failed or cancelled export never leaves an incomplete file at path. The progress is logged unless quiet is set.
+func ExportApp(ctx context.Context, appName, path string, noData bool, headers http.Header, tlsClientConfig *tls.Config, quiet bool) int64 {
+	appID, _ := applyNameToIDTransformation(appName)
+	partPath := path + ".part"
+	file, err := os.Create(partPath)
+	if err != nil {
+		log.Fatalf("could not create file '%s': %s\n", partPath, err)
+	}
+	var progress func(written, total int64)
+	if !quiet {
+		progress = exportProgress()
+	}
+	size, err := rest.ExportApp(ctx, appID, noData, GetEngineURL(), headers, tlsClientConfig, file, progress)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(partPath)
+		log.Fatalf("could not export app '%s': %s\n", appName, err)
+	}
+	if err = os.Rename(partPath, path); err != nil {
+		os.Remove(partPath)
+		log.Fatalf("could not write file '%s': %s\n", path, err)
+	}
+	log.Verbosef("Exported app '%s' to %s\n", appName, path)
+	return size
+}
+
+// exportProgress returns a function that logs the progress for every tenth of the size, or for every
+// exportProgressStep bytes if the size is unknown
+func exportProgress() func(written, total int64) {
+	var logged int64
+	return func(written, total int64) {
+		step := int64(exportProgressStep)
+		if total > 0 {
+			step = total / 10
+		}
+		if step <= 0 || written/step == logged/step {
+			return
+		}
+		logged = written
+		if total > 0 {
+			log.Infof("Exported %s of %s (%d%%)\n", FormatBytes(int(written)), FormatBytes(int(total)), written*100/total)
+		} else {
+			log.Infof("Exported %s\n", FormatBytes(int(written)))
+		}
+	}
+}
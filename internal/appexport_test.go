@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/qlik-oss/corectl/internal/rest"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func exportServer(content []byte, size int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/apps/my-app.qvf/export" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("NoData") == "true" {
+			content = content[:4]
+			size = 4
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(size))
+		w.Write(content)
+	}))
+}
+
+func TestExportApp(t *testing.T) {
+	content := []byte("qvf-content")
+	server := exportServer(content, len(content))
+	defer server.Close()
+	viper.Set("engine", server.URL)
+	defer viper.Set("engine", nil)
+
+	dir, _ := ioutil.TempDir("", "export")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "my-app.qvf")
+	size := ExportApp(context.Background(), "my-app.qvf", path, false, http.Header{}, nil, true)
+	assert.Equal(t, int64(len(content)), size)
+	exported, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, content, exported)
+	_, err = os.Stat(path + ".part")
+	assert.True(t, os.IsNotExist(err))
+
+	size = ExportApp(context.Background(), "my-app.qvf", path, true, http.Header{}, nil, true)
+	assert.Equal(t, int64(4), size)
+}
+
+func TestExportAppIncomplete(t *testing.T) {
+	content := []byte("qvf-content")
+	server := exportServer(content, len(content)+10)
+	defer server.Close()
+	engine, _ := parseEngineURL(server.URL)
+
+	written := int64(0)
+	_, err := rest.ExportApp(context.Background(), "my-app.qvf", false, engine, http.Header{}, nil, ioutil.Discard, func(n, total int64) {
+		written = n
+	})
+	assert.Error(t, err)
+	assert.Equal(t, int64(len(content)), written)
+
+	_, err = rest.ExportApp(context.Background(), "other-app.qvf", false, engine, http.Header{}, nil, ioutil.Discard, nil)
+	assert.EqualError(t, err, "could not export app: got status 404")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = rest.ExportApp(ctx, "my-app.qvf", false, engine, http.Header{}, nil, ioutil.Discard, nil)
+	assert.Error(t, err)
+}
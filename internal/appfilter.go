@@ -0,0 +1,192 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qlik-oss/enigma-go"
+)
+
+// AppFilter is a condition on the apps of an engine, parsed from an expression like 'name~sales-*'
+type AppFilter struct {
+	Field    string
+	Operator string
+	Value    string
+	regexp   *regexp.Regexp
+	time     time.Time
+	// age is set when the time is given as an age, which makes the operators compare the age of the app
+	age  bool
+	size float64
+}
+
+// Operators of app filters, the two character operators are listed first so that they are matched first
+var appFilterOperators = []string{"=~", "!=", ">=", "<=", "=", "~", ">", "<"}
+
+// Matches the field of an app filter expression
+var appFilterFieldRegexp = regexp.MustCompile(`^\s*([a-z]+)\s*`)
+
+// Multipliers of the size units allowed in size filters
+var sizeUnits = map[string]float64{"": 1, "B": 1, "KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30}
+
+// ParseAppFilter parses an expression consisting of a field, an operator and a value. The fields are name, id,
+// title, modified, reloaded and size. The operators are = and != for equality, ~ for glob patterns, =~ for
+// regular expressions and <, <=, > and >= for dates and sizes. Dates are given as 2006-01-02, as RFC 3339 time
+// stamps or as an age like 36h or 7d, in which case the age of the app is compared so that 'reloaded<7d' means
+// reloaded less than seven days ago. Sizes are given in bytes, optionally with the unit KB, MB or GB.
+func ParseAppFilter(expression string) (*AppFilter, error) {
+	match := appFilterFieldRegexp.FindStringSubmatch(expression)
+	if match == nil {
+		return nil, fmt.Errorf("invalid app filter '%s', expected a field like name, id or size", expression)
+	}
+	filter := &AppFilter{Field: match[1]}
+	rest := expression[len(match[0]):]
+	for _, operator := range appFilterOperators {
+		if strings.HasPrefix(rest, operator) {
+			filter.Operator = operator
+			filter.Value = strings.TrimSpace(rest[len(operator):])
+			break
+		}
+	}
+	if filter.Operator == "" {
+		return nil, fmt.Errorf("invalid app filter '%s', expected one of the operators %s", expression, strings.Join(appFilterOperators, " "))
+	}
+	var err error
+	switch filter.Field {
+	case "name", "id", "title":
+		switch filter.Operator {
+		case "=~":
+			filter.regexp, err = regexp.Compile(filter.Value)
+		case "~":
+			_, err = path.Match(filter.Value, "")
+		case "=", "!=":
+		default:
+			err = fmt.Errorf("the operator %s can not be used with %s", filter.Operator, filter.Field)
+		}
+	case "modified", "reloaded":
+		filter.time, filter.age, err = parseFilterTime(filter.Value)
+	case "size":
+		filter.size, err = parseFilterSize(filter.Value)
+	default:
+		err = fmt.Errorf("unknown field, expected name, id, title, modified, reloaded or size")
+	}
+	if err == nil && (filter.Field == "modified" || filter.Field == "reloaded" || filter.Field == "size") && strings.Contains(filter.Operator, "~") {
+		err = fmt.Errorf("the operator %s can not be used with %s", filter.Operator, filter.Field)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid app filter '%s': %s", expression, err)
+	}
+	return filter, nil
+}
+
+// parseFilterTime parses a date or an age, returning the point in time and whether it was given as an age
+func parseFilterTime(value string) (time.Time, bool, error) {
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil {
+			return time.Now().AddDate(0, 0, -days), true, nil
+		}
+	}
+	if age, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-age), true, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, false, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+func parseFilterSize(value string) (float64, error) {
+	number := strings.TrimRightFunc(strings.ToUpper(value), func(r rune) bool { return r >= 'A' && r <= 'Z' })
+	multiplier, ok := sizeUnits[strings.TrimSpace(strings.ToUpper(value)[len(number):])]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit in '%s', expected B, KB, MB or GB", value)
+	}
+	size, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	return size * multiplier, err
+}
+
+// Match tells whether the app matches the filter
+func (f *AppFilter) Match(doc *enigma.DocListEntry) bool {
+	switch f.Field {
+	case "name":
+		return f.matchText(doc.DocName)
+	case "id":
+		return f.matchText(doc.DocId)
+	case "title":
+		return f.matchText(doc.Title)
+	case "modified":
+		return doc.FileTime != 0 && f.compareTime(SerialTimeToTime(doc.FileTime))
+	case "reloaded":
+		reloaded, err := time.Parse(time.RFC3339, doc.LastReloadTime)
+		return err == nil && f.compareTime(reloaded)
+	case "size":
+		return f.compare(float64(doc.FileSize), f.size)
+	}
+	return false
+}
+
+func (f *AppFilter) matchText(text string) bool {
+	switch f.Operator {
+	case "=":
+		return text == f.Value
+	case "!=":
+		return text != f.Value
+	case "~":
+		matched, _ := path.Match(f.Value, text)
+		return matched
+	case "=~":
+		return f.regexp.MatchString(text)
+	}
+	return false
+}
+
+// compareTime compares the time, or the age of the app if the filter is given as an age. An app is younger the
+// later its time, so the order is reversed for ages.
+func (f *AppFilter) compareTime(t time.Time) bool {
+	if f.age {
+		return f.compare(float64(f.time.Unix()), float64(t.Unix()))
+	}
+	return f.compare(float64(t.Unix()), float64(f.time.Unix()))
+}
+
+func (f *AppFilter) compare(value, reference float64) bool {
+	switch f.Operator {
+	case "=":
+		return value == reference
+	case "!=":
+		return value != reference
+	case "<":
+		return value < reference
+	case "<=":
+		return value <= reference
+	case ">":
+		return value > reference
+	case ">=":
+		return value >= reference
+	}
+	return false
+}
+
+// FilterApps returns the apps matching all filters
+func FilterApps(docList []*enigma.DocListEntry, filters []*AppFilter) []*enigma.DocListEntry {
+	result := []*enigma.DocListEntry{}
+	for _, doc := range docList {
+		matches := true
+		for _, filter := range filters {
+			matches = matches && filter.Match(doc)
+		}
+		if matches {
+			result = append(result, doc)
+		}
+	}
+	return result
+}
+
+// SerialTimeToTime converts a serial date, the number of days since 1899-12-30, to a time
+func SerialTimeToTime(serial enigma.Float64) time.Time {
+	return time.Unix(int64((serial-25569)*86400), 0).UTC()
+}
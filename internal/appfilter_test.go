@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qlik-oss/enigma-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAppFilter(t *testing.T) {
+	filter, err := ParseAppFilter("name~sales-*")
+	assert.Nil(t, err)
+	assert.Equal(t, &AppFilter{Field: "name", Operator: "~", Value: "sales-*"}, filter)
+
+	filter, err = ParseAppFilter("size >= 1.5 MB")
+	assert.Nil(t, err)
+	assert.Equal(t, 1.5*1024*1024, filter.size)
+
+	for _, expression := range []string{"sales", "owner=me", "name>a", "size~1", "size>1PB", "modified<yesterday", "id=~("} {
+		_, err := ParseAppFilter(expression)
+		assert.NotNil(t, err, expression)
+	}
+}
+
+func TestFilterApps(t *testing.T) {
+	now := time.Now()
+	docList := []*enigma.DocListEntry{
+		{DocName: "sales-eu.qvf", DocId: "1", FileSize: 2 << 20, LastReloadTime: now.Add(-time.Hour).Format(time.RFC3339)},
+		{DocName: "sales-us.qvf", DocId: "2", FileSize: 200 << 20, LastReloadTime: now.AddDate(0, 0, -10).Format(time.RFC3339)},
+		{DocName: "finance.qvf", DocId: "3", FileSize: 1 << 20},
+	}
+	filter := func(expressions ...string) []string {
+		filters := []*AppFilter{}
+		for _, expression := range expressions {
+			filter, err := ParseAppFilter(expression)
+			assert.Nil(t, err)
+			filters = append(filters, filter)
+		}
+		ids := []string{}
+		for _, doc := range FilterApps(docList, filters) {
+			ids = append(ids, doc.DocId)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"1", "2", "3"}, filter())
+	assert.Equal(t, []string{"1", "2"}, filter("name~sales-*"))
+	assert.Equal(t, []string{"3"}, filter("name=~^fin"))
+	assert.Equal(t, []string{"1", "3"}, filter("id!=2"))
+	assert.Equal(t, []string{"2"}, filter("name~sales-*", "size>100MB"))
+	assert.Equal(t, []string{"1"}, filter("reloaded<7d"))
+	assert.Equal(t, []string{"2"}, filter("reloaded>7d"))
+	assert.Equal(t, []string{"2"}, filter("reloaded>=48h"))
+	assert.Equal(t, []string{"1"}, filter("reloaded>"+now.AddDate(0, 0, -2).Format("2006-01-02")))
+	assert.Equal(t, []string{"2"}, filter("reloaded<"+now.AddDate(0, 0, -2).Format("2006-01-02")))
+}
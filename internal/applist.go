@@ -0,0 +1,222 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qlik-oss/enigma-go"
+)
+
+// AppListEntry is an app in the doc list of the engine, along with all attributes of its qMeta
+type AppListEntry struct {
+	*enigma.DocListEntry
+	// Meta are the attributes of qMeta, which depend on the engine. Qlik Sense Enterprise for example sets
+	// description, published and owner.
+	Meta map[string]interface{}
+}
+
+// AppListColumns are the columns that can be printed for apps, besides the qMeta attributes given as meta.<name>
+var AppListColumns = []string{"id", "name", "title", "description", "size", "modified", "last-reloaded", "readonly", "thumbnail", "published", "owner", "section-access"}
+
+// DefaultAppListColumns are the columns printed for apps unless other columns are requested
+var DefaultAppListColumns = []string{"id", "name", "last-reloaded", "readonly", "title"}
+
+// appSortFields are the fields apps can be sorted by
+var appSortFields = []string{"name", "id", "title", "size", "modified", "reloaded"}
+
+// ListApps returns the apps in the engine. The doc list is read raw since enigma only keeps the name of qMeta.
+func ListApps(ctx context.Context, global *enigma.Global) ([]*AppListEntry, error) {
+	raw, err := global.GetDocListRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	docList := []*enigma.DocListEntry{}
+	if err = json.Unmarshal(raw, &docList); err != nil {
+		return nil, err
+	}
+	metas := []struct {
+		Meta map[string]interface{} `json:"qMeta"`
+	}{}
+	if err = json.Unmarshal(raw, &metas); err != nil {
+		return nil, err
+	}
+	apps := make([]*AppListEntry, len(docList))
+	for i, doc := range docList {
+		apps[i] = &AppListEntry{DocListEntry: doc, Meta: metas[i].Meta}
+	}
+	return apps, nil
+}
+
+// FilterAppList returns the apps whose name, id or title matches the glob pattern, if set, and that match all
+// filters
+func FilterAppList(apps []*AppListEntry, pattern string, filters []*AppFilter) ([]*AppListEntry, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern '%s': %s", pattern, err)
+	}
+	result := []*AppListEntry{}
+	for _, app := range apps {
+		if pattern != "" && !matchAny(pattern, app.DocName, app.DocId, app.Title) {
+			continue
+		}
+		matches := true
+		for _, filter := range filters {
+			matches = matches && filter.Match(app.DocListEntry)
+		}
+		if matches {
+			result = append(result, app)
+		}
+	}
+	return result, nil
+}
+
+func matchAny(pattern string, texts ...string) bool {
+	for _, text := range texts {
+		if matched, _ := path.Match(pattern, text); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// SortApps sorts the apps by name, id, title, size, modified or reloaded, in reverse order if reverse is set.
+// Apps that are equal keep their order.
+func SortApps(apps []*AppListEntry, field string, reverse bool) error {
+	var less func(a, b *AppListEntry) bool
+	switch field {
+	case "name":
+		less = func(a, b *AppListEntry) bool { return strings.ToLower(a.DocName) < strings.ToLower(b.DocName) }
+	case "id":
+		less = func(a, b *AppListEntry) bool { return a.DocId < b.DocId }
+	case "title":
+		less = func(a, b *AppListEntry) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) }
+	case "size":
+		less = func(a, b *AppListEntry) bool { return a.FileSize < b.FileSize }
+	case "modified":
+		less = func(a, b *AppListEntry) bool { return a.FileTime < b.FileTime }
+	case "reloaded":
+		less = func(a, b *AppListEntry) bool { return reloadTime(a).Before(reloadTime(b)) }
+	default:
+		return fmt.Errorf("can not sort apps by '%s', expected one of %s", field, strings.Join(appSortFields, ", "))
+	}
+	sort.SliceStable(apps, func(i, j int) bool {
+		if reverse {
+			return less(apps[j], apps[i])
+		}
+		return less(apps[i], apps[j])
+	})
+	return nil
+}
+
+// reloadTime returns the last reload time of the app, or the zero time if it has not been reloaded
+func reloadTime(app *AppListEntry) time.Time {
+	reloaded, _ := time.Parse(time.RFC3339, app.LastReloadTime)
+	return reloaded
+}
+
+// ValidateAppListColumns checks that the columns are known or are qMeta attributes
+func ValidateAppListColumns(columns []string) error {
+	for _, column := range columns {
+		if strings.HasPrefix(column, "meta.") && len(column) > len("meta.") {
+			continue
+		}
+		known := false
+		for _, name := range AppListColumns {
+			known = known || column == name
+		}
+		if !known {
+			return fmt.Errorf("unknown column '%s', expected one of %s or meta.<attribute>", column, strings.Join(AppListColumns, ", "))
+		}
+	}
+	return nil
+}
+
+// Description returns the description of the app in qMeta
+func (a *AppListEntry) Description() string {
+	description, _ := a.Meta["description"].(string)
+	return description
+}
+
+// Published tells whether the app is published according to qMeta
+func (a *AppListEntry) Published() bool {
+	published, _ := a.Meta["published"].(bool)
+	return published
+}
+
+// Owner returns the owner of the app in qMeta, which is either a name or an object with the name or user id
+func (a *AppListEntry) Owner() string {
+	switch owner := a.Meta["owner"].(type) {
+	case string:
+		return owner
+	case map[string]interface{}:
+		for _, key := range []string{"name", "userId", "id"} {
+			if value, ok := owner[key].(string); ok && value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// HasThumbnail tells whether the app has a thumbnail
+func (a *AppListEntry) HasThumbnail() bool {
+	return a.Thumbnail != nil && a.Thumbnail.Url != ""
+}
+
+// Modified returns the last modified time of the app, or the zero time if the engine does not tell it
+func (a *AppListEntry) Modified() time.Time {
+	if a.FileTime == 0 {
+		return time.Time{}
+	}
+	return SerialTimeToTime(a.FileTime)
+}
+
+// Column returns the value of the column for the app, see AppListColumns
+func (a *AppListEntry) Column(column string) string {
+	switch column {
+	case "id":
+		return a.DocId
+	case "name":
+		return a.DocName
+	case "title":
+		return a.Title
+	case "description":
+		return a.Description()
+	case "size":
+		return strconv.FormatInt(int64(a.FileSize), 10)
+	case "modified":
+		if modified := a.Modified(); !modified.IsZero() {
+			return modified.Format(time.RFC3339)
+		}
+		return ""
+	case "last-reloaded":
+		return a.LastReloadTime
+	case "readonly":
+		return strconv.FormatBool(a.ReadOnly)
+	case "thumbnail":
+		return strconv.FormatBool(a.HasThumbnail())
+	case "published":
+		return strconv.FormatBool(a.Published())
+	case "owner":
+		return a.Owner()
+	case "section-access":
+		return strconv.FormatBool(a.HasSectionAccess)
+	}
+	if strings.HasPrefix(column, "meta.") {
+		switch value := a.Meta[strings.TrimPrefix(column, "meta.")].(type) {
+		case nil:
+			return ""
+		case string:
+			return value
+		default:
+			encoded, _ := json.Marshal(value)
+			return string(encoded)
+		}
+	}
+	return ""
+}
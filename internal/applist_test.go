@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qlik-oss/corectl/test/fakeengine"
+	"github.com/qlik-oss/enigma-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListApps(t *testing.T) {
+	ctx := context.Background()
+	engine := fakeengine.New()
+	assert.Nil(t, engine.Start())
+	defer engine.Close()
+	sales := engine.AddApp("sales-2023")
+	sales.FileSize = 2048
+	sales.Meta = map[string]interface{}{
+		"description": "Sales figures",
+		"published":   true,
+		"owner":       map[string]interface{}{"name": "Jane", "userId": "jane"},
+		"stream":      map[string]interface{}{"name": "Everyone"},
+	}
+	engine.AddApp("finance").FileSize = 4096
+	engine.AddApp("sales-2024").FileSize = 1024
+	global, err := enigma.Dialer{}.Dial(ctx, engine.URL, nil)
+	assert.Nil(t, err)
+	defer global.DisconnectFromServer()
+
+	apps, err := ListApps(ctx, global)
+	assert.Nil(t, err)
+	assert.Len(t, apps, 3)
+
+	apps, err = FilterAppList(apps, "sales-*", nil)
+	assert.Nil(t, err)
+	assert.Len(t, apps, 2)
+	assert.Nil(t, SortApps(apps, "size", false))
+	assert.Equal(t, "sales-2024", apps[0].DocId)
+	assert.Nil(t, SortApps(apps, "size", true))
+	assert.Equal(t, "sales-2023", apps[0].DocId)
+	assert.Error(t, SortApps(apps, "owner", false))
+
+	app := apps[0]
+	assert.Equal(t, "Sales figures", app.Column("description"))
+	assert.Equal(t, "2048", app.Column("size"))
+	assert.Equal(t, "true", app.Column("published"))
+	assert.Equal(t, "Jane", app.Column("owner"))
+	assert.Equal(t, "false", app.Column("thumbnail"))
+	assert.Equal(t, `{"name":"Everyone"}`, app.Column("meta.stream"))
+	assert.Equal(t, "", apps[1].Column("owner"))
+
+	assert.Nil(t, ValidateAppListColumns([]string{"id", "owner", "meta.stream"}))
+	assert.Error(t, ValidateAppListColumns([]string{"id", "stream"}))
+	assert.Error(t, ValidateAppListColumns([]string{"meta."}))
+
+	filter, err := ParseAppFilter("size>=2KB")
+	assert.Nil(t, err)
+	apps, err = ListApps(ctx, global)
+	assert.Nil(t, err)
+	apps, err = FilterAppList(apps, "", []*AppFilter{filter})
+	assert.Nil(t, err)
+	assert.Len(t, apps, 2)
+	_, err = FilterAppList(apps, "[", nil)
+	assert.Error(t, err)
+}
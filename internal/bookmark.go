@@ -45,9 +45,15 @@ func ListBookmarks(ctx context.Context, doc *enigma.Doc) []NamedItem {
 			}`),
 		},
 	}
-	sessionObject, _ := doc.CreateSessionObject(ctx, props)
+	sessionObject, err := doc.CreateSessionObject(ctx, props)
+	if err != nil {
+		log.Fatalf("could not list bookmarks: %s\n", err)
+	}
 	defer doc.DestroySessionObject(ctx, sessionObject.GenericId)
-	layout, _ := sessionObject.GetLayout(ctx)
+	layout, err := sessionObject.GetLayout(ctx)
+	if err != nil {
+		log.Fatalf("could not list bookmarks: %s\n", err)
+	}
 	result := []NamedItem{}
 	for _, item := range layout.BookmarkList.Items {
 		parsedRawData := &ParsedEntityListData{}
@@ -57,6 +63,26 @@ func ListBookmarks(ctx context.Context, doc *enigma.Doc) []NamedItem {
 	return result
 }
 
+// collectBookmarks returns the properties of all bookmarks in the app
+func collectBookmarks(ctx context.Context, doc *enigma.Doc) []JSONWithOrder {
+	bookmarks := ListBookmarks(ctx, doc)
+	result := make([]JSONWithOrder, len(bookmarks))
+	errs := forEach(len(bookmarks), "", func(index int) error {
+		bookmark, err := doc.GetBookmark(ctx, bookmarks[index].ID)
+		if err != nil {
+			return fmt.Errorf("could not get bookmark %s: %s", bookmarks[index].ID, err)
+		}
+		props, err := bookmark.GetPropertiesRaw(ctx)
+		if err != nil {
+			return fmt.Errorf("could not get properties of bookmark %s: %s", bookmarks[index].ID, err)
+		}
+		result[index] = JSONWithOrder{props, index}
+		return nil
+	})
+	failOnErrors(errs, "could not fetch all bookmarks of the app")
+	return result
+}
+
 // SetBookmarks adds all bookmarks that match the specified glob pattern
 func SetBookmarks(ctx context.Context, doc *enigma.Doc, commandLineGlobPattern string) {
 	paths, err := getEntityPaths(commandLineGlobPattern, "bookmarks")
@@ -78,7 +104,9 @@ func SetBookmarks(ctx context.Context, doc *enigma.Doc, commandLineGlobPattern s
 			if err != nil {
 				log.Fatalf("validation error in file %s: %s\n", path, err)
 			}
-			err = setBookmark(ctx, doc, bm.Info.Id, raw)
+			err = retrySet(ctx, "bookmark", bm.Info.Id, func() error {
+				return setBookmark(ctx, doc, bm.Info.Id, raw)
+			})
 			if err != nil {
 				log.Fatalln(err)
 			}
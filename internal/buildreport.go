@@ -0,0 +1,89 @@
+package internal
+
+import "time"
+
+type (
+	// BuildReport records each phase of a build and each entity that failed to be created or
+	// updated as a test case
+	BuildReport struct {
+		Cases []*TestCase
+		phase *TestCase
+		start time.Time
+	}
+
+	// entityError is an error that occurred when setting a single entity
+	entityError struct {
+		id  string
+		err error
+	}
+)
+
+// buildReport is the report of the running build, or nil if no report was requested
+var buildReport *BuildReport
+
+func (e *entityError) Error() string {
+	return e.err.Error()
+}
+
+// StartBuildReport starts recording the build
+func StartBuildReport() *BuildReport {
+	buildReport = &BuildReport{Cases: []*TestCase{}}
+	return buildReport
+}
+
+// Phase ends the current phase and starts the next one. Calls on a nil report are ignored.
+func (r *BuildReport) Phase(name string) {
+	if r == nil {
+		return
+	}
+	r.endPhase()
+	r.phase = &TestCase{Name: name, Group: "build"}
+	r.start = time.Now()
+}
+
+// Fail marks the current phase as failed. Calls on a nil report are ignored.
+func (r *BuildReport) Fail(message string) {
+	if r == nil {
+		return
+	}
+	if r.phase == nil {
+		r.Phase("build")
+	}
+	if r.phase.Failure == "" {
+		r.phase.Failure = message
+	}
+}
+
+// Finish ends the current phase and returns all test cases
+func (r *BuildReport) Finish() []*TestCase {
+	r.endPhase()
+	return r.Cases
+}
+
+func (r *BuildReport) endPhase() {
+	if r.phase != nil {
+		r.phase.Duration = time.Since(r.start)
+		r.Cases = append(r.Cases, r.phase)
+		r.phase = nil
+	}
+}
+
+// newEntityError associates the error with the id of the entity, nil is returned if there is no error
+func newEntityError(id string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &entityError{id: id, err: err}
+}
+
+// reportEntityError adds a failed test case for the entity to the build report, if any
+func reportEntityError(kind string, err error) {
+	if buildReport == nil || err == nil {
+		return
+	}
+	name := kind
+	if entityErr, ok := err.(*entityError); ok {
+		name = entityErr.id
+	}
+	buildReport.Cases = append(buildReport.Cases, &TestCase{Name: name, Group: kind, Failure: err.Error()})
+}
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildReport(t *testing.T) {
+	report := StartBuildReport()
+	defer func() { buildReport = nil }()
+	report.Phase("connections")
+	report.Phase("measures")
+	reportEntityError("measures", newEntityError("measure-sales", errors.New("invalid expression")))
+	reportEntityError("measures", errors.New("could not parse file"))
+	report.Fail("One or more measures failed to be created or updated")
+	cases := report.Finish()
+
+	assert.Len(t, cases, 4)
+	assert.Equal(t, "connections", cases[0].Name)
+	assert.True(t, cases[0].Passed())
+	assert.Equal(t, "measure-sales", cases[1].Name)
+	assert.Equal(t, "measures", cases[1].Group)
+	assert.Equal(t, "invalid expression", cases[1].Failure)
+	assert.Equal(t, "measures", cases[2].Name)
+	assert.Equal(t, "measures", cases[3].Name)
+	assert.Equal(t, "build", cases[3].Group)
+	assert.Equal(t, "One or more measures failed to be created or updated", cases[3].Failure)
+}
+
+func TestBuildReportNil(t *testing.T) {
+	var report *BuildReport
+	report.Phase("connections")
+	report.Fail("failed")
+	reportEntityError("measures", errors.New("failed"))
+	assert.Nil(t, newEntityError("measure-sales", nil))
+}
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/qlik-oss/corectl/internal/log"
+)
+
+// Exit codes used when a command is stopped before it finished
+const (
+	ExitCodeTimeout     = 124
+	ExitCodeInterrupted = 130
+)
+
+// Time to wait for the engine to acknowledge that a request is cancelled
+const cancelTimeout = 5 * time.Second
+
+// NewCancellableContext returns a context that is cancelled when the timeout expires, if it is positive,
+// or when the process is interrupted. A second interrupt terminates the process immediately.
+func NewCancellableContext(timeout time.Duration) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			log.Warnf("Received %s, stopping...\n", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+		// Restore the default behavior
+		signal.Stop(signals)
+	}()
+	return ctx
+}
+
+// ExitCode returns the exit code of a command that failed with the context: ExitCodeTimeout if the timeout
+// expired, ExitCodeInterrupted if the process was interrupted and otherwise 1.
+func ExitCode(ctx context.Context) int {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return ExitCodeTimeout
+	case context.Canceled:
+		return ExitCodeInterrupted
+	}
+	return 1
+}
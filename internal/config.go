@@ -41,12 +41,27 @@ type ConnectionsConfig struct {
 
 // GetConnectionsConfig returns a the current connections configuration.
 func GetConnectionsConfig() *ConnectionsConfig {
+	if app := selectedProjectApp; app != nil {
+		if conn, ok := app.properties["connections"].(map[interface{}]interface{}); ok {
+			// Read connections set by the app in the apps list of the config file.
+			// Not using viper due to camel case insensitivity.
+			config := &ConnectionsConfig{}
+			strConfig, err := convertMap(map[interface{}]interface{}{"connections": conn})
+			if err == nil {
+				err = reMarshal(strConfig, config)
+			}
+			if err != nil {
+				log.Fatalf("could not parse the connections of the app '%s': %s\n", app.App, err)
+			}
+			return config
+		}
+	}
 	var config *ConnectionsConfig
 	conn := viper.Get("connections")
 	switch conn.(type) {
 	case string:
 		// Read connections from a separate yaml file.
-		connFile := RelativeToProject(conn.(string))
+		connFile := ConfigPath("connections")
 		config = ReadConnectionsFile(connFile)
 	case map[string]interface{}:
 		// Read connections from config file.
@@ -141,6 +156,9 @@ func ReadConfig(explicitConfigFile, certPath string, withContext bool) {
 	case configFile != "":
 		ConfigDir = filepath.Dir(configFile)
 		log.Verboseln("Using config file: " + configFile)
+		if selectedProjectApp != nil {
+			log.Verboseln("Using the properties of the app '" + selectedProjectApp.App + "' in the config file")
+		}
 	case withContext:
 		log.Verboseln("No config file specified, using context.")
 	default:
@@ -202,10 +220,20 @@ func readConfig(configPath string, withContext bool) {
 		mergeContext(config)
 	}
 	validateProps(*config, configPath)
+	projectApps = readProjectApps((*config)["apps"], configPath)
 	err := subEnvVars(config)
 	if err != nil {
 		log.Fatalf("bad substitution in '%s': %s\n", configPath, err)
 	}
+	setViperConfig(*config)
+	// The app is known first when the config is read, since it can be set by the config file as well as by --app
+	if configPath != "" && selectProjectApp(viper.GetString("app"), *config) {
+		setViperConfig(*config)
+	}
+}
+
+// setViperConfig makes viper use the config as if it was read from the config file
+func setViperConfig(config map[interface{}]interface{}) {
 	configBytes, err := yaml.Marshal(config)
 	if err != nil {
 		log.Fatalf("unexpected error after parsing config: %s\n", err)
@@ -0,0 +1,216 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/qlik-oss/enigma-go"
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	// DataTest is an assertion on the value of an expression, optionally with selections applied
+	DataTest struct {
+		Name       string                 `yaml:"name"`
+		Expression string                 `yaml:"expression"`
+		Selections map[string]interface{} `yaml:"selections"`
+		Equals     interface{}            `yaml:"equals"`
+		Tolerance  float64                `yaml:"tolerance"`
+		Min        *float64               `yaml:"min"`
+		Max        *float64               `yaml:"max"`
+		File       string                 `yaml:"-"`
+	}
+
+	// TestCase is the result of a test or of a step that is reported as one
+	TestCase struct {
+		Name     string        `json:"name"`
+		Group    string        `json:"group,omitempty"`
+		Duration time.Duration `json:"duration"`
+		Failure  string        `json:"failure,omitempty"`
+	}
+
+	dataTestsFile struct {
+		Tests []*DataTest `yaml:"tests"`
+	}
+)
+
+// Passed reports whether the test case did not fail
+func (c *TestCase) Passed() bool {
+	return c.Failure == ""
+}
+
+// ReadDataTests reads the tests in the files matching the glob patterns. If no patterns are
+// given the tests section of the config file is used.
+func ReadDataTests(patterns []string) []*DataTest {
+	paths := []string{}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil || len(matches) == 0 {
+			log.Fatalf("could not find test file '%s'\n", pattern)
+		}
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		if configFile == "" {
+			log.Fatalln("no test files specified and no config file found")
+		}
+		paths = []string{configFile}
+	}
+	tests := []*DataTest{}
+	for _, path := range paths {
+		tests = append(tests, readDataTestsFile(path)...)
+	}
+	if len(tests) == 0 {
+		log.Fatalln("no tests found")
+	}
+	return tests
+}
+
+func readDataTestsFile(path string) []*DataTest {
+	source, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Fatalf("could not find test file '%s'\n", path)
+	}
+	tempConfig := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal(source, &tempConfig); err != nil {
+		log.Fatalf("invalid syntax in test file '%s': %s\n", path, err)
+	}
+	if err := subEnvVars(&tempConfig); err != nil {
+		log.Fatalf("bad substitution in '%s': %s\n", path, err)
+	}
+	strConfig, err := convertMap(tempConfig)
+	if err != nil {
+		log.Fatalf("could not parse test file '%s': %s\n", path, err)
+	}
+	file := &dataTestsFile{}
+	if err := reMarshal(strConfig, file); err != nil {
+		log.Fatalf("could not parse tests in '%s': %s\n", path, err)
+	}
+	for i, test := range file.Tests {
+		if test.Expression == "" {
+			log.Fatalf("test %d in '%s' has no expression\n", i+1, path)
+		}
+		if test.Equals == nil && test.Min == nil && test.Max == nil {
+			log.Fatalf("test %d in '%s' has neither equals, min nor max\n", i+1, path)
+		}
+		if test.Name == "" {
+			test.Name = test.Expression
+		}
+		test.File = filepath.Base(path)
+	}
+	return file.Tests
+}
+
+// RunDataTests evaluates each test in the app. Selections are cleared before each test.
+func RunDataTests(ctx context.Context, doc *enigma.Doc, tests []*DataTest) []*TestCase {
+	ensureModelExists(ctx, doc)
+	result := []*TestCase{}
+	for _, test := range tests {
+		start := time.Now()
+		failure := runDataTest(ctx, doc, test)
+		result = append(result, &TestCase{Name: test.Name, Group: test.File, Duration: time.Since(start), Failure: failure})
+	}
+	doc.ClearAll(ctx, false, "$")
+	return result
+}
+
+// runDataTest returns a description of the failure or an empty string if the test passed
+func runDataTest(ctx context.Context, doc *enigma.Doc, test *DataTest) string {
+	if err := doc.ClearAll(ctx, false, "$"); err != nil {
+		return fmt.Sprintf("could not clear selections: %s", err)
+	}
+	fieldNames := []string{}
+	for fieldName := range test.Selections {
+		fieldNames = append(fieldNames, fieldName)
+	}
+	sort.Strings(fieldNames)
+	for _, fieldName := range fieldNames {
+		if err := selectValues(ctx, doc, fieldName, test.Selections[fieldName]); err != nil {
+			return err.Error()
+		}
+	}
+	object := createHypercube(ctx, doc, nil, createMeasures([]string{test.Expression}), nil)
+	if object == nil {
+		return "could not create hypercube"
+	}
+	defer doc.DestroySessionObject(ctx, object.GenericId)
+	layout, err := object.GetLayout(ctx)
+	if err != nil {
+		return fmt.Sprintf("could not get hypercube layout: %s", err)
+	}
+	if len(layout.HyperCube.MeasureInfo) != 0 && layout.HyperCube.MeasureInfo[0].Error != nil {
+		return fmt.Sprintf("could not evaluate expression, error returned code: %d", layout.HyperCube.MeasureInfo[0].Error.ErrorCode)
+	}
+	if len(layout.HyperCube.DataPages) == 0 || len(layout.HyperCube.DataPages[0].Matrix) == 0 {
+		return "the expression returned no value"
+	}
+	cell := layout.HyperCube.DataPages[0].Matrix[0][0]
+	return checkValue(test, cell.Text, float64(cell.Num))
+}
+
+// checkValue compares the value with the expectations of the test
+func checkValue(test *DataTest, text string, number float64) string {
+	isNumber := !math.IsNaN(number) && !math.IsInf(number, 0)
+	switch expected := test.Equals.(type) {
+	case nil:
+	case int, float64:
+		expectedNumber, _ := strconv.ParseFloat(fmt.Sprint(expected), 64)
+		if !isNumber {
+			return fmt.Sprintf("expected %v but got '%s'", expected, text)
+		}
+		if math.Abs(number-expectedNumber) > test.Tolerance {
+			if test.Tolerance > 0 {
+				return fmt.Sprintf("expected %v ± %v but got %v", expected, test.Tolerance, number)
+			}
+			return fmt.Sprintf("expected %v but got %v", expected, number)
+		}
+	default:
+		if text != fmt.Sprint(expected) {
+			return fmt.Sprintf("expected '%v' but got '%s'", expected, text)
+		}
+	}
+	if test.Min != nil || test.Max != nil {
+		if !isNumber {
+			return fmt.Sprintf("expected a number but got '%s'", text)
+		}
+		if test.Min != nil && number < *test.Min {
+			return fmt.Sprintf("expected at least %v but got %v", *test.Min, number)
+		}
+		if test.Max != nil && number > *test.Max {
+			return fmt.Sprintf("expected at most %v but got %v", *test.Max, number)
+		}
+	}
+	return ""
+}
+
+// selectValues selects one or a list of values in the field
+func selectValues(ctx context.Context, doc *enigma.Doc, fieldName string, values interface{}) error {
+	field, err := doc.GetField(ctx, fieldName, "")
+	if err != nil {
+		return fmt.Errorf("could not find field '%s': %s", fieldName, err)
+	}
+	list, ok := values.([]interface{})
+	if !ok {
+		list = []interface{}{values}
+	}
+	fieldValues := []*enigma.FieldValue{}
+	for _, value := range list {
+		fieldValue := &enigma.FieldValue{Text: fmt.Sprint(value)}
+		if number, err := strconv.ParseFloat(fieldValue.Text, 64); err == nil {
+			fieldValue.IsNumeric = true
+			fieldValue.Number = enigma.Float64(number)
+		}
+		fieldValues = append(fieldValues, fieldValue)
+	}
+	if _, err := field.SelectValues(ctx, fieldValues, false, false); err != nil {
+		return fmt.Errorf("could not select %v in field '%s': %s", list, fieldName, err)
+	}
+	return nil
+}
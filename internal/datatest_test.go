@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadDataTestsFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "datatest")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sales.test.yml")
+	ioutil.WriteFile(path, []byte(`tests:
+  - name: EU sales
+    expression: Sum(Sales)
+    selections:
+      Region: EU
+      Year: [2019, 2020]
+    equals: 1234
+    tolerance: 0.5
+  - expression: Count(Customer)
+    min: 10
+`), 0644)
+	tests := readDataTestsFile(path)
+	assert.Len(t, tests, 2)
+	assert.Equal(t, "EU sales", tests[0].Name)
+	assert.Equal(t, "EU", tests[0].Selections["Region"])
+	assert.Equal(t, []interface{}{2019, 2020}, tests[0].Selections["Year"])
+	assert.Equal(t, 1234, tests[0].Equals)
+	assert.Equal(t, 0.5, tests[0].Tolerance)
+	assert.Equal(t, "sales.test.yml", tests[0].File)
+	assert.Equal(t, "Count(Customer)", tests[1].Name)
+	assert.Equal(t, 10.0, *tests[1].Min)
+	assert.Nil(t, tests[1].Max)
+}
+
+func TestCheckValue(t *testing.T) {
+	min, max := 10.0, 20.0
+	assert.Equal(t, "", checkValue(&DataTest{Equals: 1234, Tolerance: 0.5}, "1234.4", 1234.4))
+	assert.Equal(t, "expected 1234 ± 0.5 but got 1235", checkValue(&DataTest{Equals: 1234, Tolerance: 0.5}, "1235", 1235))
+	assert.Equal(t, "expected 12.5 but got 12", checkValue(&DataTest{Equals: 12.5}, "12", 12))
+	assert.Equal(t, "expected 12 but got '-'", checkValue(&DataTest{Equals: 12}, "-", math.NaN()))
+	assert.Equal(t, "", checkValue(&DataTest{Equals: "EUR"}, "EUR", math.NaN()))
+	assert.Equal(t, "expected 'EUR' but got 'SEK'", checkValue(&DataTest{Equals: "EUR"}, "SEK", math.NaN()))
+	assert.Equal(t, "", checkValue(&DataTest{Min: &min, Max: &max}, "15", 15))
+	assert.Equal(t, "expected at least 10 but got 5", checkValue(&DataTest{Min: &min}, "5", 5))
+	assert.Equal(t, "expected at most 20 but got 25", checkValue(&DataTest{Max: &max}, "25", 25))
+	assert.Equal(t, "expected a number but got 'abc'", checkValue(&DataTest{Min: &min}, "abc", math.NaN()))
+}
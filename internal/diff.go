@@ -0,0 +1,231 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/qlik-oss/enigma-go"
+)
+
+type (
+	// AppContent is the content of an app compared by diff, serialized as indented JSON by identifier
+	AppContent struct {
+		Script      string
+		Connections map[string]string
+		Variables   map[string]string
+		Measures    map[string]string
+		Dimensions  map[string]string
+		Sheets      map[string]string
+		Objects     map[string]string
+		Tables      []*enigma.TableRecord
+	}
+
+	// AppDiff contains the differences between two apps
+	AppDiff struct {
+		Script      string       `json:"script,omitempty"`
+		Connections *EntityDiff  `json:"connections"`
+		Variables   *EntityDiff  `json:"variables"`
+		Measures    *EntityDiff  `json:"measures"`
+		Dimensions  *EntityDiff  `json:"dimensions"`
+		Sheets      *EntityDiff  `json:"sheets"`
+		Objects     *EntityDiff  `json:"objects"`
+		Tables      []*TableDiff `json:"tables"`
+	}
+
+	// EntityDiff lists the entities only in the other app (added), only in the first app (removed) and in both
+	// apps but with different properties (changed)
+	EntityDiff struct {
+		Added   []string         `json:"added,omitempty"`
+		Removed []string         `json:"removed,omitempty"`
+		Changed []*ChangedEntity `json:"changed,omitempty"`
+	}
+
+	// ChangedEntity is an entity with different properties in the two apps along with a unified diff of the properties
+	ChangedEntity struct {
+		ID   string `json:"id"`
+		Diff string `json:"diff"`
+	}
+
+	// TableDiff describes a table that has been added, removed or changed in number of rows or fields
+	TableDiff struct {
+		Name          string   `json:"name"`
+		Status        string   `json:"status"`
+		Rows          int      `json:"rows"`
+		OtherRows     int      `json:"otherRows"`
+		AddedFields   []string `json:"addedFields,omitempty"`
+		RemovedFields []string `json:"removedFields,omitempty"`
+	}
+
+	// connectionContent are the compared properties of a connection, the id and dates differ between apps
+	connectionContent struct {
+		Type             string `json:"qType"`
+		ConnectionString string `json:"qConnectionString"`
+		UserName         string `json:"qUserName,omitempty"`
+	}
+)
+
+// Empty reports whether there are no differences
+func (d *EntityDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// ReadAppContent fetches the script, connections, entities and tables of the app. Entities are fetched
+// the same way as by unbuild.
+func ReadAppContent(ctx context.Context, doc *enigma.Doc) *AppContent {
+	script, err := doc.GetScript(ctx)
+	if err != nil {
+		log.Fatalf("could not retrieve script: %s\n", err)
+	}
+	content := &AppContent{
+		Script:      script,
+		Connections: map[string]string{},
+		Variables:   map[string]string{},
+		Measures:    map[string]string{},
+		Dimensions:  map[string]string{},
+		Sheets:      map[string]string{},
+		Objects:     map[string]string{},
+	}
+	connections, err := doc.GetConnections(ctx)
+	if err != nil {
+		log.Fatalf("could not retrieve connections: %s\n", err)
+	}
+	for _, connection := range connections {
+		content.Connections[connection.Name] = string(marshalOrFail(connectionContent{connection.Type, connection.ConnectionString, connection.UserName}))
+	}
+	measures, dimensions, objects := collectEntities(ctx, doc, "")
+	addEntityContent(content.Measures, measures)
+	addEntityContent(content.Dimensions, dimensions)
+	for _, object := range objects {
+		props := &UnbuildEntityProperies{}
+		json.Unmarshal(object.JSON, props)
+		if props.QProperty != nil {
+			props = props.QProperty
+		}
+		if props.QInfo.QType == "sheet" {
+			content.Sheets[props.QInfo.QId] = normalizeJSON(object.JSON)
+		} else {
+			content.Objects[props.QInfo.QId] = normalizeJSON(object.JSON)
+		}
+	}
+	for _, variable := range collectVariables(ctx, doc, "") {
+		// Variables are identified by name, the ids are generated when they are created
+		props := map[string]interface{}{}
+		json.Unmarshal(variable.JSON, &props)
+		if info, ok := props["qInfo"].(map[string]interface{}); ok {
+			delete(info, "qId")
+		}
+		name, _ := props["qName"].(string)
+		content.Variables[name] = string(marshalOrFail(props))
+	}
+	content.Tables, _, err = doc.GetTablesAndKeys(ctx, &enigma.Size{}, &enigma.Size{}, 0, false, false, false)
+	if err != nil {
+		log.Fatalf("could not retrieve tables and keys: %s\n", err)
+	}
+	return content
+}
+
+func addEntityContent(entities map[string]string, array []JSONWithOrder) {
+	for _, entity := range array {
+		props := &UnbuildEntityProperies{}
+		json.Unmarshal(entity.JSON, props)
+		entities[props.QInfo.QId] = normalizeJSON(entity.JSON)
+	}
+}
+
+// normalizeJSON indents the JSON and sorts the keys of all objects
+func normalizeJSON(raw json.RawMessage) string {
+	var value interface{}
+	json.Unmarshal(raw, &value)
+	return string(marshalOrFail(value))
+}
+
+// DiffApps compares the content of two apps
+func DiffApps(content, other *AppContent) *AppDiff {
+	return &AppDiff{
+		Script:      unifiedDiff("script", "other/script", content.Script, other.Script),
+		Connections: diffEntities("connections", content.Connections, other.Connections),
+		Variables:   diffEntities("variables", content.Variables, other.Variables),
+		Measures:    diffEntities("measures", content.Measures, other.Measures),
+		Dimensions:  diffEntities("dimensions", content.Dimensions, other.Dimensions),
+		Sheets:      diffEntities("sheets", content.Sheets, other.Sheets),
+		Objects:     diffEntities("objects", content.Objects, other.Objects),
+		Tables:      diffTables(content.Tables, other.Tables),
+	}
+}
+
+func diffEntities(kind string, entities, other map[string]string) *EntityDiff {
+	result := &EntityDiff{}
+	for _, id := range sortedKeys(entities) {
+		otherEntity, exists := other[id]
+		switch {
+		case !exists:
+			result.Removed = append(result.Removed, id)
+		case otherEntity != entities[id]:
+			diff := unifiedDiff(kind+"/"+id, "other/"+kind+"/"+id, entities[id], otherEntity)
+			result.Changed = append(result.Changed, &ChangedEntity{ID: id, Diff: diff})
+		}
+	}
+	for _, id := range sortedKeys(other) {
+		if _, exists := entities[id]; !exists {
+			result.Added = append(result.Added, id)
+		}
+	}
+	return result
+}
+
+func diffTables(tables, other []*enigma.TableRecord) []*TableDiff {
+	result := []*TableDiff{}
+	otherByName := map[string]*enigma.TableRecord{}
+	for _, table := range other {
+		otherByName[table.Name] = table
+	}
+	found := map[string]bool{}
+	for _, table := range tables {
+		otherTable, exists := otherByName[table.Name]
+		if !exists {
+			result = append(result, &TableDiff{Name: table.Name, Status: "removed", Rows: table.NoOfRows})
+			continue
+		}
+		found[table.Name] = true
+		diff := &TableDiff{Name: table.Name, Status: "changed", Rows: table.NoOfRows, OtherRows: otherTable.NoOfRows}
+		fields, otherFields := tableFieldNames(table), tableFieldNames(otherTable)
+		for _, field := range sortedKeys(otherFields) {
+			if _, exists := fields[field]; !exists {
+				diff.AddedFields = append(diff.AddedFields, field)
+			}
+		}
+		for _, field := range sortedKeys(fields) {
+			if _, exists := otherFields[field]; !exists {
+				diff.RemovedFields = append(diff.RemovedFields, field)
+			}
+		}
+		if diff.Rows != diff.OtherRows || len(diff.AddedFields) > 0 || len(diff.RemovedFields) > 0 {
+			result = append(result, diff)
+		}
+	}
+	for _, table := range other {
+		if !found[table.Name] {
+			result = append(result, &TableDiff{Name: table.Name, Status: "added", OtherRows: table.NoOfRows})
+		}
+	}
+	return result
+}
+
+func tableFieldNames(table *enigma.TableRecord) map[string]string {
+	names := map[string]string{}
+	for _, field := range table.Fields {
+		names[field.Name] = field.Name
+	}
+	return names
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
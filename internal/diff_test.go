@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/qlik-oss/enigma-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n11\n12\n13\n"
+	assert.Equal(t, `--- a
++++ b
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`, unifiedDiff("a", "b", a, b))
+	assert.Equal(t, "", unifiedDiff("a", "b", a, a))
+}
+
+func TestDiffApps(t *testing.T) {
+	content := &AppContent{
+		Script:   "LOAD 1 as a AutoGenerate 1;\n",
+		Measures: map[string]string{"m1": "{\n  \"qDef\": \"Sum(a)\"\n}", "m2": "{}"},
+		Tables: []*enigma.TableRecord{
+			{Name: "T1", NoOfRows: 10, Fields: []*enigma.FieldInTableData{{Name: "a"}, {Name: "b"}}},
+			{Name: "T2", NoOfRows: 5},
+		},
+	}
+	other := &AppContent{
+		Script:   "LOAD 1 as a AutoGenerate 1;\n",
+		Measures: map[string]string{"m1": "{\n  \"qDef\": \"Sum(b)\"\n}", "m3": "{}"},
+		Tables: []*enigma.TableRecord{
+			{Name: "T1", NoOfRows: 12, Fields: []*enigma.FieldInTableData{{Name: "a"}, {Name: "c"}}},
+			{Name: "T3", NoOfRows: 1},
+		},
+	}
+	diff := DiffApps(content, other)
+	assert.Equal(t, "", diff.Script)
+	assert.True(t, diff.Dimensions.Empty())
+	assert.Equal(t, []string{"m3"}, diff.Measures.Added)
+	assert.Equal(t, []string{"m2"}, diff.Measures.Removed)
+	assert.Equal(t, "--- measures/m1\n+++ other/measures/m1\n@@ -1,3 +1,3 @@\n {\n-  \"qDef\": \"Sum(a)\"\n+  \"qDef\": \"Sum(b)\"\n }\n", diff.Measures.Changed[0].Diff)
+	assert.Equal(t, []*TableDiff{
+		{Name: "T1", Status: "changed", Rows: 10, OtherRows: 12, AddedFields: []string{"c"}, RemovedFields: []string{"b"}},
+		{Name: "T2", Status: "removed", Rows: 5},
+		{Name: "T3", Status: "added", OtherRows: 1},
+	}, diff.Tables)
+}
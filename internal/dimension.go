@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/qlik-oss/corectl/internal/log"
 	"github.com/qlik-oss/enigma-go"
@@ -16,6 +17,26 @@ type Dimension struct {
 	Info *enigma.NxInfo `json:"qInfo,omitempty"`
 }
 
+// DimensionDef describes a master dimension created from the command line. More than one
+// field makes the dimension a drill-down group.
+type DimensionDef struct {
+	ID     string
+	Label  string
+	Fields []string
+	Tags   []string
+}
+
+type dimensionProperties struct {
+	Info    *enigma.NxInfo       `json:"qInfo"`
+	Dim     *libraryDimensionDef `json:"qDim"`
+	MetaDef *MasterItemMetaDef   `json:"qMetaDef"`
+}
+
+type libraryDimensionDef struct {
+	*enigma.NxLibraryDimensionDef
+	Title string `json:"title,omitempty"`
+}
+
 func (d Dimension) validate() error {
 	if d.Info == nil {
 		return errors.New("missing qInfo attribute")
@@ -46,9 +67,15 @@ func ListDimensions(ctx context.Context, doc *enigma.Doc) []NamedItem {
 			}`),
 		},
 	}
-	sessionObject, _ := doc.CreateSessionObject(ctx, props)
+	sessionObject, err := doc.CreateSessionObject(ctx, props)
+	if err != nil {
+		log.Fatalf("could not list dimensions: %s\n", err)
+	}
 	defer doc.DestroySessionObject(ctx, sessionObject.GenericId)
-	layout, _ := sessionObject.GetLayout(ctx)
+	layout, err := sessionObject.GetLayout(ctx)
+	if err != nil {
+		log.Fatalf("could not list dimensions: %s\n", err)
+	}
 	unsortedResult := make(map[string]*NamedItem)
 	keys := make([]string, len(unsortedResult))
 	for _, item := range layout.DimensionList.Items {
@@ -78,31 +105,28 @@ func SetDimensions(ctx context.Context, doc *enigma.Doc, commandLineGlobPattern
 		if err != nil {
 			log.Fatalf("could not parse file %s: %s\n", path, err)
 		}
-		ch := make(chan error)
-
-		for _, raw := range rawEntities {
-			go func(raw json.RawMessage) {
-				var dim Dimension
-				err := json.Unmarshal(raw, &dim)
-				if err != nil {
-					ch <- fmt.Errorf("could not parse data in file %s: %s", path, err)
-					return
-				}
-				err = dim.validate()
-				if err != nil {
-					ch <- fmt.Errorf("validation error in file %s: %s", path, err)
-					return
-				}
-				ch <- setDimension(ctx, doc, dim.Info.Id, raw)
-			}(raw)
-		}
+		errs := forEach(len(rawEntities), "", func(i int) error {
+			raw := rawEntities[i]
+			var dim Dimension
+			err := json.Unmarshal(raw, &dim)
+			if err != nil {
+				return newEntityError(path, fmt.Errorf("could not parse data in file %s: %s", path, err))
+			}
+			err = dim.validate()
+			if err != nil {
+				return newEntityError(path, fmt.Errorf("validation error in file %s: %s", path, err))
+			}
+			return newEntityError(dim.Info.Id, retrySet(ctx, "dimension", dim.Info.Id, func() error {
+				return setDimension(ctx, doc, dim.Info.Id, raw)
+			}))
+		})
 
-		// Loop through the responses and see if there are any failures, if so exit with a fatal
+		// See if there are any failures, if so exit with a fatal
 		success := true
-		for range rawEntities {
-			err := <-ch
+		for _, err := range errs {
 			if err != nil {
 				log.Errorln(err)
+				reportEntityError("dimensions", err)
 				success = false
 			}
 		}
@@ -133,3 +157,67 @@ func setDimension(ctx context.Context, doc *enigma.Doc, dimensionID string, raw
 	}
 	return nil
 }
+
+// CreateDimension validates the fields of the dimension definition and creates or updates the
+// master dimension. The generated properties are returned so they can be stored in the project.
+func CreateDimension(ctx context.Context, doc *enigma.Doc, def DimensionDef) json.RawMessage {
+	if len(def.Fields) == 0 {
+		log.Fatalln("no field specified")
+	}
+	for _, field := range def.Fields {
+		// Calculated dimensions start with '=', anything else is a field name where ']' is escaped as ']]'
+		expression := "[" + strings.ReplaceAll(field, "]", "]]") + "]"
+		if strings.HasPrefix(field, "=") {
+			expression = strings.TrimPrefix(field, "=")
+		}
+		if err := checkExpression(ctx, doc, expression); err != nil {
+			log.Fatalln(err)
+		}
+	}
+	title := def.Label
+	if title == "" {
+		title = strings.Join(def.Fields, "-")
+	}
+	id := def.ID
+	if id == "" {
+		id = buildEntityID("dimension", title)
+	}
+	grouping := "N"
+	if len(def.Fields) > 1 {
+		grouping = "H"
+	}
+	props := &dimensionProperties{
+		Info: &enigma.NxInfo{
+			Id:   id,
+			Type: "dimension",
+		},
+		Dim: &libraryDimensionDef{
+			NxLibraryDimensionDef: &enigma.NxLibraryDimensionDef{
+				Grouping:  grouping,
+				FieldDefs: def.Fields,
+			},
+			Title: title,
+		},
+		MetaDef: &MasterItemMetaDef{
+			Title: title,
+			Tags:  def.Tags,
+		},
+	}
+	raw := marshalOrFail(props)
+	if err := setDimension(ctx, doc, id, raw); err != nil {
+		log.Fatalln(err)
+	}
+	log.Info("Dimension set with ID: ")
+	log.Quietln(id)
+	return raw
+}
+
+// AppendDimensionToFile adds the dimension properties to the dimensions json file
+func AppendDimensionToFile(path string, raw json.RawMessage) {
+	var dim Dimension
+	json.Unmarshal(raw, &dim)
+	if err := appendEntityToFile(path, dim.Info.Id, raw); err != nil {
+		log.Fatalf("could not append dimension to %s: %s\n", path, err)
+	}
+	log.Verboseln("Appended dimension " + dim.Info.Id + " to " + path)
+}
@@ -0,0 +1,29 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/qlik-oss/corectl/test/fakeengine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateDimensionEscapesFieldNames(t *testing.T) {
+	engine := fakeengine.New()
+	assert.Nil(t, engine.Start())
+	defer engine.Close()
+	engine.AddApp("app")
+	expressions := []string{}
+	engine.Handle("CheckExpression", func(session *fakeengine.Session, handle int, params []json.RawMessage) (interface{}, error) {
+		expression := ""
+		json.Unmarshal(params[0], &expression)
+		expressions = append(expressions, expression)
+		return nil, fakeengine.ErrNotHandled
+	})
+
+	global, doc := openFakeApp(t, engine, "app")
+	defer global.DisconnectFromServer()
+	CreateDimension(context.Background(), doc, DimensionDef{Fields: []string{"Sales [EUR]", "=Year(Date)"}})
+	assert.Equal(t, []string{"[Sales [EUR]]]", "Year(Date)"}, expressions)
+}
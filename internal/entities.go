@@ -1,10 +1,13 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/qlik-oss/corectl/internal/log"
 	"github.com/qlik-oss/enigma-go"
@@ -40,11 +43,23 @@ type (
 	QMetaDef struct {
 		Title string `json:"title"`
 	}
+
+	// MasterItemMetaDef is the qMetaDef of master measures and dimensions
+	MasterItemMetaDef struct {
+		Title       string   `json:"title,omitempty"`
+		Description string   `json:"description,omitempty"`
+		Tags        []string `json:"tags,omitempty"`
+	}
 )
 
 // If commandLineGlobPattern is set the paths will be from that glob pattern
 // otherwise glob patterns will be from the config file using the configEntityParam
 func getEntityPaths(commandLineGlobPattern string, configEntityParam string) ([]string, error) {
+	return findEntityPaths(commandLineGlobPattern, configEntityParam, true)
+}
+
+// findEntityPaths works like getEntityPaths but only warns about patterns without matches if warn is set
+func findEntityPaths(commandLineGlobPattern string, configEntityParam string, warn bool) ([]string, error) {
 	var paths []string
 	var err error
 	if commandLineGlobPattern != "" {
@@ -52,16 +67,17 @@ func getEntityPaths(commandLineGlobPattern string, configEntityParam string) ([]
 		if err != nil {
 			return paths, err
 		}
-		if len(paths) == 0 {
+		if len(paths) == 0 && warn {
 			log.Warnf("No '%s' found for pattern %s\n", configEntityParam, commandLineGlobPattern)
 		}
 	} else {
 		if ConfigDir == "" {
 			return paths, nil
 		}
+		baseDir := configBaseDir(configEntityParam)
 		currentWorkingDir, _ := os.Getwd()
 		defer os.Chdir(currentWorkingDir)
-		os.Chdir(ConfigDir)
+		os.Chdir(baseDir)
 
 		globPatterns := viper.GetStringSlice(configEntityParam)
 		var pathMatches []string
@@ -70,13 +86,15 @@ func getEntityPaths(commandLineGlobPattern string, configEntityParam string) ([]
 			if err != nil {
 				log.Fatalf("could not interpret glob pattern '%s': %s\n", pattern, err)
 			} else if len(pathMatches) == 0 {
-				log.Warnf("No '%s' found for pattern %s\n", configEntityParam, pattern)
+				if warn {
+					log.Warnf("No '%s' found for pattern %s\n", configEntityParam, pattern)
+				}
 			} else {
 				paths = append(paths, pathMatches...)
 			}
 		}
 		for i, path := range paths {
-			paths[i] = ConfigDir + "/" + path
+			paths[i] = baseDir + "/" + path
 		}
 	}
 	return paths, nil
@@ -102,3 +120,55 @@ func parseEntityFile(path string) (entities []json.RawMessage, err error) {
 	}
 	return
 }
+
+// checkExpression asks the engine to validate the expression and returns an error describing
+// the first problem found, if any.
+func checkExpression(ctx context.Context, doc *enigma.Doc, expression string) error {
+	errorMessage, badFieldNames, _, err := doc.CheckExpression(ctx, expression, []string{})
+	if err != nil {
+		return fmt.Errorf("could not check expression '%s': %s", expression, err)
+	}
+	if errorMessage != "" {
+		return fmt.Errorf("invalid expression '%s': %s", expression, errorMessage)
+	}
+	if len(badFieldNames) > 0 {
+		return fmt.Errorf("invalid expression '%s': bad field name(s)", expression)
+	}
+	return nil
+}
+
+// buildEntityID creates an identifier like 'measure-total-sales' from the entity type and a title
+func buildEntityID(qType, title string) string {
+	id := qType + "-" + strings.ToLower(title)
+	id = matchAllNonAlphaNumeric.ReplaceAllString(id, `-`)
+	return strings.Trim(id, "-")
+}
+
+// appendEntityToFile adds the entity to the json array in the file. An entity with the same
+// qId already present in the file is replaced. The file is created if it does not exist.
+func appendEntityToFile(path string, entityID string, raw json.RawMessage) error {
+	entities := []json.RawMessage{}
+	if _, err := os.Stat(path); err == nil {
+		entities, err = parseEntityFile(path)
+		if err != nil {
+			return fmt.Errorf("could not parse file %s: %s", path, err)
+		}
+	}
+	replaced := false
+	for i, entity := range entities {
+		var existing struct {
+			Info *enigma.NxInfo `json:"qInfo"`
+		}
+		if err := json.Unmarshal(entity, &existing); err != nil {
+			return fmt.Errorf("could not parse file %s: %s", path, err)
+		}
+		if existing.Info != nil && existing.Info.Id == entityID {
+			entities[i] = raw
+			replaced = true
+		}
+	}
+	if !replaced {
+		entities = append(entities, raw)
+	}
+	return ioutil.WriteFile(path, marshalOrFail(entities), 0644)
+}
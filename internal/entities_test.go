@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildEntityID(t *testing.T) {
+	assert.Equal(t, "measure-total-revenue", buildEntityID("measure", "Total Revenue"))
+	assert.Equal(t, "dimension-country-city", buildEntityID("dimension", "Country-City"))
+	assert.Equal(t, "measure-sum-sales", buildEntityID("measure", "Sum(Sales)"))
+}
+
+func TestAppendEntityToFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "corectl")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "measures.json")
+
+	first := json.RawMessage(`{"qInfo":{"qId":"m1","qType":"measure"},"qMeasure":{"qDef":"Sum(a)"}}`)
+	second := json.RawMessage(`{"qInfo":{"qId":"m2","qType":"measure"},"qMeasure":{"qDef":"Sum(b)"}}`)
+	updated := json.RawMessage(`{"qInfo":{"qId":"m1","qType":"measure"},"qMeasure":{"qDef":"Sum(c)"}}`)
+
+	assert.Nil(t, appendEntityToFile(path, "m1", first))
+	assert.Nil(t, appendEntityToFile(path, "m2", second))
+	assert.Nil(t, appendEntityToFile(path, "m1", updated))
+
+	entities, err := parseEntityFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(entities))
+	assert.Contains(t, string(entities[0]), "Sum(c)")
+	assert.Contains(t, string(entities[1]), "Sum(b)")
+}
+
+func TestAppendEntityToInvalidFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "corectl")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "measures.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`[1, 2]`), 0644))
+
+	err = appendEntityToFile(path, "m1", json.RawMessage(`{"qInfo":{"qId":"m1","qType":"measure"}}`))
+	assert.Error(t, err)
+	content, _ := ioutil.ReadFile(path)
+	assert.Equal(t, `[1, 2]`, string(content))
+}
@@ -15,7 +15,7 @@ func Eval(ctx context.Context, doc *enigma.Doc, args []string) {
 	ensureModelExists(ctx, doc)
 
 	measures, dims := argumentsToMeasuresAndDims(args)
-	object, _ := doc.CreateSessionObject(ctx, &enigma.GenericObjectProperties{
+	object, err := doc.CreateSessionObject(ctx, &enigma.GenericObjectProperties{
 		Info: &enigma.NxInfo{
 			Type: "my-straight-hypercube",
 		},
@@ -28,6 +28,9 @@ func Eval(ctx context.Context, doc *enigma.Doc, args []string) {
 			}},
 		},
 	})
+	if err != nil {
+		log.Fatalf("could not create session object: %s\n", err)
+	}
 	layout, err := object.GetLayout(ctx)
 
 	if err != nil {
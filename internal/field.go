@@ -54,7 +54,7 @@ func getFieldContentAsTable(ctx context.Context, doc *enigma.Doc, fieldName stri
 
 func getFieldContent(ctx context.Context, doc *enigma.Doc, fieldName string, count int) []string {
 
-	object, _ := doc.CreateSessionObject(ctx, &enigma.GenericObjectProperties{
+	object, err := doc.CreateSessionObject(ctx, &enigma.GenericObjectProperties{
 		Info: &enigma.NxInfo{
 			Type: "my-straight-hypercube",
 		},
@@ -75,6 +75,9 @@ func getFieldContent(ctx context.Context, doc *enigma.Doc, fieldName string, cou
 			},
 		},
 	})
+	if err != nil {
+		log.Fatalf("could not create session object: %s\n", err)
+	}
 
 	layout, err := object.GetLayout(ctx)
 	if err != nil {
@@ -20,7 +20,7 @@ func applySelection(ctx context.Context, doc *enigma.Doc, fieldName string, valu
 
 func createHypercube(ctx context.Context, doc *enigma.Doc, dimensions []*enigma.NxDimension, measures []*enigma.NxMeasure, sortOrder []int) *enigma.GenericObject {
 
-	object, _ := doc.CreateSessionObject(ctx, &enigma.GenericObjectProperties{
+	object, err := doc.CreateSessionObject(ctx, &enigma.GenericObjectProperties{
 		Info: &enigma.NxInfo{
 			Type: "my-straight-hypercube",
 		},
@@ -35,6 +35,9 @@ func createHypercube(ctx context.Context, doc *enigma.Doc, dimensions []*enigma.
 			InterColumnSortOrder: sortOrder,
 		},
 	})
+	if err != nil {
+		log.Fatalf("could not create session object: %s\n", err)
+	}
 
 	return object
 }
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path"
 	"runtime"
@@ -15,6 +16,11 @@ var knownAppsFilePath = path.Join(userHomeDir(), ".corectl", "knownApps.yml")
 // Fetch a matching app id from known apps for a specified app name
 // If not found return the appName and found bool set to false
 func applyNameToIDTransformation(appName string) (appID string, found bool) {
+	return knownAppID(GetEngineURL(), appName)
+}
+
+// knownAppID fetches a matching app id from known apps of the engine for a specified app name
+func knownAppID(engineURL *url.URL, appName string) (appID string, found bool) {
 	apps := getKnownApps()
 
 	if apps == nil {
@@ -22,7 +28,6 @@ func applyNameToIDTransformation(appName string) (appID string, found bool) {
 		return appName, false
 	}
 
-	engineURL := GetEngineURL()
 	host := engineURL.Host
 
 	if id, exists := apps[host][appName]; exists {
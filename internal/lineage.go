@@ -0,0 +1,475 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/qlik-oss/enigma-go"
+)
+
+type (
+	// LineageNode is a field, variable, master item or object in the dependency graph
+	LineageNode struct {
+		ID    string `json:"id"`
+		Type  string `json:"type"`
+		Title string `json:"title,omitempty"`
+	}
+
+	// LineageEdge means that the node From references the node To in one of its expressions
+	LineageEdge struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+
+	// DependencyGraph describes which entities in an app reference which fields, variables and master items.
+	// Nodes are keyed by '<type>:<id>', see LineageNode.Key.
+	DependencyGraph struct {
+		Nodes map[string]*LineageNode
+		Uses  map[string][]string
+	}
+
+	// Lineage is the part of a dependency graph that depends on a specific field
+	Lineage struct {
+		Field string         `json:"field"`
+		Nodes []*LineageNode `json:"nodes"`
+		Edges []*LineageEdge `json:"edges"`
+	}
+
+	entityExpressions struct {
+		node        *LineageNode
+		expressions []string
+		libraryIDs  []string
+	}
+)
+
+// Property keys whose string values are expressions
+var expressionKeys = map[string]bool{
+	"qDef":             true,
+	"qExpressions":     true,
+	"qLabelExpression": true,
+	"qDefinition":      true,
+	"qExpr":            true,
+	"qv":               true,
+}
+
+// Key returns the key of the node in the dependency graph
+func (n *LineageNode) Key() string {
+	return n.Type + ":" + n.ID
+}
+
+// BuildDependencyGraph reads all measures, dimensions, variables and objects in the app and
+// links them to the fields, variables and master items used in their expressions.
+func BuildDependencyGraph(ctx context.Context, doc *enigma.Doc, fieldNames []string) *DependencyGraph {
+	return newDependencyGraph(fieldNames, readEntityExpressions(ctx, doc))
+}
+
+// GetFieldLineage returns all measures, dimensions, variables and objects that depend on the field
+func GetFieldLineage(ctx context.Context, doc *enigma.Doc, fieldName string) *Lineage {
+	fieldNames := getSortedFieldsNames(ctx, doc, nil)
+	inModel := false
+	for _, name := range fieldNames {
+		if name == fieldName {
+			inModel = true
+		}
+	}
+	if !inModel {
+		log.Warnf("Field '%s' is not part of the data model\n", fieldName)
+		fieldNames = append(fieldNames, fieldName)
+	}
+	return BuildDependencyGraph(ctx, doc, fieldNames).Lineage(fieldName)
+}
+
+func newDependencyGraph(fieldNames []string, entities []*entityExpressions) *DependencyGraph {
+	graph := &DependencyGraph{
+		Nodes: map[string]*LineageNode{},
+		Uses:  map[string][]string{},
+	}
+	for _, name := range fieldNames {
+		graph.addNode(&LineageNode{ID: name, Type: "field"})
+	}
+	variableNames := map[string]bool{}
+	for _, entity := range entities {
+		graph.addNode(entity.node)
+		if entity.node.Type == "variable" {
+			variableNames[entity.node.ID] = true
+		}
+	}
+	for _, entity := range entities {
+		from := entity.node.Key()
+		for _, expression := range entity.expressions {
+			identifiers, expansions := scanExpression(expression)
+			for _, identifier := range identifiers {
+				if _, isField := graph.Nodes["field:"+identifier]; isField {
+					graph.addEdge(from, "field:"+identifier)
+				} else if variableNames[identifier] {
+					graph.addEdge(from, "variable:"+identifier)
+				}
+			}
+			for _, expansion := range expansions {
+				if variableNames[expansion] {
+					graph.addEdge(from, "variable:"+expansion)
+				}
+			}
+		}
+		for _, libraryID := range entity.libraryIDs {
+			if _, ok := graph.Nodes["measure:"+libraryID]; ok {
+				graph.addEdge(from, "measure:"+libraryID)
+			} else if _, ok := graph.Nodes["dimension:"+libraryID]; ok {
+				graph.addEdge(from, "dimension:"+libraryID)
+			}
+		}
+	}
+	return graph
+}
+
+func (g *DependencyGraph) addNode(node *LineageNode) {
+	if _, exists := g.Nodes[node.Key()]; !exists {
+		g.Nodes[node.Key()] = node
+	}
+}
+
+func (g *DependencyGraph) addEdge(from, to string) {
+	if from == to {
+		return
+	}
+	for _, existing := range g.Uses[from] {
+		if existing == to {
+			return
+		}
+	}
+	g.Uses[from] = append(g.Uses[from], to)
+}
+
+// UsedBy returns the keys of all nodes directly referencing the node with the given key
+func (g *DependencyGraph) UsedBy(key string) []string {
+	result := []string{}
+	for from, uses := range g.Uses {
+		for _, to := range uses {
+			if to == key {
+				result = append(result, from)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// ReachableFrom returns the keys of all nodes directly or indirectly referenced by nodes of the given type
+func (g *DependencyGraph) ReachableFrom(nodeType string) map[string]bool {
+	reached := map[string]bool{}
+	queue := []string{}
+	for key, node := range g.Nodes {
+		if node.Type == nodeType {
+			queue = append(queue, key)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, to := range g.Uses[current] {
+			if !reached[to] {
+				reached[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+	return reached
+}
+
+// Lineage returns all nodes that directly or indirectly depend on the field
+func (g *DependencyGraph) Lineage(fieldName string) *Lineage {
+	root := "field:" + fieldName
+	lineage := &Lineage{
+		Field: fieldName,
+		Nodes: []*LineageNode{},
+		Edges: []*LineageEdge{},
+	}
+	if _, exists := g.Nodes[root]; !exists {
+		return lineage
+	}
+	visited := map[string]bool{root: true}
+	queue := []string{root}
+	lineage.Nodes = append(lineage.Nodes, g.Nodes[root])
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, from := range g.UsedBy(current) {
+			lineage.Edges = append(lineage.Edges, &LineageEdge{From: from, To: current})
+			if !visited[from] {
+				visited[from] = true
+				lineage.Nodes = append(lineage.Nodes, g.Nodes[from])
+				queue = append(queue, from)
+			}
+		}
+	}
+	return lineage
+}
+
+func readEntityExpressions(ctx context.Context, doc *enigma.Doc) []*entityExpressions {
+	allInfos, err := doc.GetAllInfos(ctx)
+	if err != nil {
+		log.Fatalf("could not retrieve the entities of the app: %s\n", err)
+	}
+	variables := ListVariables(ctx, doc)
+
+	entities := make([]*entityExpressions, len(allInfos)+len(variables))
+	errs := forEach(len(entities), "", func(i int) (err error) {
+		if i < len(allInfos) {
+			entities[i], err = readEntityExpressionsForInfo(ctx, doc, allInfos[i])
+		} else {
+			entities[i], err = readVariableExpressions(ctx, doc, variables[i-len(allInfos)])
+		}
+		return err
+	})
+	failOnErrors(errs, "could not read all entities of the app")
+
+	result := []*entityExpressions{}
+	for _, entity := range entities {
+		if entity != nil {
+			result = append(result, entity)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].node.Key() < result[j].node.Key()
+	})
+	return result
+}
+
+func readVariableExpressions(ctx context.Context, doc *enigma.Doc, item NamedItem) (*entityExpressions, error) {
+	variable, err := doc.GetVariableByName(ctx, item.Title)
+	if err != nil {
+		return nil, fmt.Errorf("could not get variable %s: %s", item.Title, err)
+	} else if variable.Handle == 0 {
+		return nil, nil
+	}
+	rawProps, err := variable.GetPropertiesRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get properties of variable %s: %s", item.Title, err)
+	}
+	return newEntityExpressions(&LineageNode{ID: item.Title, Type: "variable", Title: item.Title}, rawProps), nil
+}
+
+func readEntityExpressionsForInfo(ctx context.Context, doc *enigma.Doc, item *enigma.NxInfo) (*entityExpressions, error) {
+	var rawProps json.RawMessage
+	switch item.Type {
+	case "variable", "bookmark":
+		return nil, nil
+	case "measure":
+		measure, err := doc.GetMeasure(ctx, item.Id)
+		if err != nil {
+			return nil, fmt.Errorf("could not get measure %s: %s", item.Id, err)
+		} else if measure.Handle == 0 {
+			return nil, nil
+		}
+		if rawProps, err = measure.GetPropertiesRaw(ctx); err != nil {
+			return nil, fmt.Errorf("could not get properties of measure %s: %s", item.Id, err)
+		}
+	case "dimension":
+		dimension, err := doc.GetDimension(ctx, item.Id)
+		if err != nil {
+			return nil, fmt.Errorf("could not get dimension %s: %s", item.Id, err)
+		} else if dimension.Handle == 0 {
+			return nil, nil
+		}
+		if rawProps, err = dimension.GetPropertiesRaw(ctx); err != nil {
+			return nil, fmt.Errorf("could not get properties of dimension %s: %s", item.Id, err)
+		}
+	default:
+		object, err := doc.GetObject(ctx, item.Id)
+		if err != nil {
+			return nil, fmt.Errorf("could not get object %s: %s", item.Id, err)
+		} else if object.Type == "" {
+			return nil, nil
+		}
+		if rawProps, err = object.GetPropertiesRaw(ctx); err != nil {
+			return nil, fmt.Errorf("could not get properties of object %s: %s", item.Id, err)
+		}
+	}
+	return newEntityExpressions(&LineageNode{ID: item.Id, Type: nodeType(item.Type)}, rawProps), nil
+}
+
+// nodeType maps a qType to the node type, all generic objects are 'object'
+func nodeType(qType string) string {
+	switch qType {
+	case "measure", "dimension", "variable":
+		return qType
+	}
+	return "object"
+}
+
+func newEntityExpressions(node *LineageNode, rawProps json.RawMessage) *entityExpressions {
+	var props map[string]interface{}
+	if err := json.Unmarshal(rawProps, &props); err != nil {
+		return nil
+	}
+	entity := &entityExpressions{node: node}
+	node.Title = titleFromProperties(props)
+	if node.Type == "object" {
+		if info, ok := props["qInfo"].(map[string]interface{}); ok {
+			if qType, ok := info["qType"].(string); ok && node.Title == "" {
+				node.Title = qType
+			}
+		}
+	}
+	collectExpressions(props, "", entity)
+	return entity
+}
+
+func titleFromProperties(props map[string]interface{}) string {
+	if meta, ok := props["qMetaDef"].(map[string]interface{}); ok {
+		if title, ok := meta["title"].(string); ok && title != "" {
+			return title
+		}
+	}
+	if title, ok := props["title"].(string); ok && title != "" {
+		return title
+	}
+	if name, ok := props["qName"].(string); ok {
+		return name
+	}
+	return ""
+}
+
+// collectExpressions walks the property tree and collects expressions and master item references
+func collectExpressions(value interface{}, key string, entity *entityExpressions) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for childKey, child := range v {
+			collectExpressions(child, childKey, entity)
+		}
+	case []interface{}:
+		for _, child := range v {
+			if key == "qFieldDefs" {
+				// Field definitions are plain field names unless they start with '='
+				if fieldDef, ok := child.(string); ok && !strings.HasPrefix(fieldDef, "=") {
+					entity.expressions = append(entity.expressions, "["+strings.Replace(fieldDef, "]", "]]", -1)+"]")
+					continue
+				}
+			}
+			collectExpressions(child, key, entity)
+		}
+	case string:
+		if v == "" {
+			return
+		}
+		switch {
+		case key == "qLibraryId":
+			entity.libraryIDs = append(entity.libraryIDs, v)
+		case expressionKeys[key] || key == "qFieldDefs":
+			entity.expressions = append(entity.expressions, strings.TrimPrefix(v, "="))
+		case strings.HasPrefix(v, "="):
+			entity.expressions = append(entity.expressions, v[1:])
+		}
+	}
+}
+
+// scanExpression finds the names an expression refers to. Identifiers are field or variable
+// names (bare, [bracketed], "double quoted" or `back quoted`) that are not function calls,
+// expansions are the names used in dollar-sign expansions like $(vName).
+// String literals and comments are skipped.
+func scanExpression(expression string) (identifiers []string, expansions []string) {
+	runes := []rune(expression)
+	n := len(runes)
+	for i := 0; i < n; i++ {
+		r := runes[i]
+		switch {
+		case r == '\'':
+			i = skipQuoted(runes, i, '\'')
+		case r == '/' && i+1 < n && runes[i+1] == '/':
+			for i < n && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < n && runes[i+1] == '*':
+			i += 2
+			for i < n && !(runes[i] == '*' && i+1 < n && runes[i+1] == '/') {
+				i++
+			}
+			i++
+		case r == '[' || r == '"' || r == '`':
+			closing := r
+			if r == '[' {
+				closing = ']'
+			}
+			end := skipQuoted(runes, i, closing)
+			name := strings.Replace(string(runes[i+1:minInt(end, n)]), string([]rune{closing, closing}), string(closing), -1)
+			identifiers = append(identifiers, name)
+			i = end
+		case r == '$' && i+1 < n && runes[i+1] == '(':
+			depth := 0
+			start := i + 2
+			j := i + 1
+			for ; j < n; j++ {
+				if runes[j] == '(' {
+					depth++
+				} else if runes[j] == ')' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			content := strings.TrimSpace(string(runes[start:minInt(j, n)]))
+			if strings.HasPrefix(content, "=") {
+				innerIdentifiers, innerExpansions := scanExpression(content[1:])
+				identifiers = append(identifiers, innerIdentifiers...)
+				expansions = append(expansions, innerExpansions...)
+			} else {
+				if paren := strings.Index(content, "("); paren >= 0 {
+					content = content[:paren]
+				}
+				expansions = append(expansions, strings.TrimSpace(content))
+			}
+			i = j
+		case isIdentifierStart(r):
+			start := i
+			for i+1 < n && isIdentifierPart(runes[i+1]) {
+				i++
+			}
+			name := string(runes[start : i+1])
+			next := i + 1
+			for next < n && unicode.IsSpace(runes[next]) {
+				next++
+			}
+			if next < n && runes[next] == '(' {
+				// Function call
+				continue
+			}
+			identifiers = append(identifiers, name)
+		}
+	}
+	return
+}
+
+// skipQuoted returns the index of the closing quote, doubled quotes are treated as escaped
+func skipQuoted(runes []rune, start int, closing rune) int {
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] == closing {
+			if i+1 < len(runes) && runes[i+1] == closing {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(runes)
+}
+
+func isIdentifierStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_' || r == '@' || r == '%' || r == '#'
+}
+
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || unicode.IsDigit(r) || r == '.' || r == '$'
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/qlik-oss/corectl/test/fakeengine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanExpression(t *testing.T) {
+	identifiers, expansions := scanExpression(`Sum({<Region={'EU'}>} [Sales Amount]) / Count(DISTINCT "Order Id") * $(vRate)`)
+	assert.Equal(t, []string{"Region", "Sales Amount", "DISTINCT", "Order Id"}, identifiers)
+	assert.Equal(t, []string{"vRate"}, expansions)
+
+	identifiers, expansions = scanExpression(`If(a > 1, 'b', c) // d
+/* e */ + $(=Max(f)) + $(vParam(1, 2))`)
+	assert.Equal(t, []string{"a", "c", "f"}, identifiers)
+	assert.Equal(t, []string{"vParam"}, expansions)
+
+	identifiers, _ = scanExpression(`[a]]b] & 'it''s' & g`)
+	assert.Equal(t, []string{"a]b", "g"}, identifiers)
+}
+
+func TestDependencyGraphLineage(t *testing.T) {
+	measure := newEntityExpressions(&LineageNode{ID: "rev", Type: "measure"},
+		json.RawMessage(`{"qInfo":{"qId":"rev","qType":"measure"},"qMeasure":{"qDef":"Sum(Sales) * vRate"},"qMetaDef":{"title":"Revenue"}}`))
+	variable := newEntityExpressions(&LineageNode{ID: "vRate", Type: "variable"},
+		json.RawMessage(`{"qInfo":{"qId":"v1","qType":"variable"},"qName":"vRate","qDefinition":"Only(Rate)"}`))
+	chart := newEntityExpressions(&LineageNode{ID: "chart", Type: "object"},
+		json.RawMessage(`{"qInfo":{"qId":"chart","qType":"barchart"},"qHyperCubeDef":{"qDimensions":[{"qDef":{"qFieldDefs":["Region"]}}],"qMeasures":[{"qLibraryId":"rev"}]}}`))
+	graph := newDependencyGraph([]string{"Sales", "Rate", "Region", "Unused"}, []*entityExpressions{measure, variable, chart})
+
+	assert.Equal(t, "Revenue", graph.Nodes["measure:rev"].Title)
+	assert.Equal(t, []string{"object:chart"}, graph.UsedBy("measure:rev"))
+	assert.Equal(t, []string{"object:chart"}, graph.UsedBy("field:Region"))
+	assert.Equal(t, []string{}, graph.UsedBy("field:Unused"))
+
+	lineage := graph.Lineage("Rate")
+	keys := []string{}
+	for _, node := range lineage.Nodes {
+		keys = append(keys, node.Key())
+	}
+	assert.Equal(t, []string{"field:Rate", "variable:vRate", "measure:rev", "object:chart"}, keys)
+	assert.Equal(t, 3, len(lineage.Edges))
+}
+
+func TestReadEntityExpressions(t *testing.T) {
+	ctx := context.Background()
+	engine := fakeengine.New()
+	assert.Nil(t, engine.Start())
+	defer engine.Close()
+	engine.AddApp("app")
+	global, doc := openFakeApp(t, engine, "app")
+	defer global.DisconnectFromServer()
+	_, err := doc.CreateMeasureRaw(ctx, []byte(`{"qInfo":{"qId":"rev","qType":"measure"},"qMeasure":{"qDef":"Sum(Sales)"}}`))
+	assert.Nil(t, err)
+	_, err = doc.CreateVariableExRaw(ctx, []byte(`{"qInfo":{"qId":"v1","qType":"variable"},"qName":"vRate","qDefinition":"Only(Rate)"}`))
+	assert.Nil(t, err)
+	_, err = doc.CreateObjectRaw(ctx, []byte(`{"qInfo":{"qId":"chart","qType":"barchart"},"qHyperCubeDef":{"qMeasures":[{"qLibraryId":"rev"}]}}`))
+	assert.Nil(t, err)
+	_, err = doc.CreateBookmarkRaw(ctx, []byte(`{"qInfo":{"qId":"b1","qType":"bookmark"}}`))
+	assert.Nil(t, err)
+
+	keys := []string{}
+	for _, entity := range readEntityExpressions(ctx, doc) {
+		keys = append(keys, entity.node.Key())
+	}
+	assert.Equal(t, []string{"measure:rev", "object:chart", "variable:vRate"}, keys)
+}
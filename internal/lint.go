@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/spf13/viper"
+)
+
+// LintFinding is a problem found by one of the linters
+type LintFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Table    string `json:"table,omitempty"`
+	Field    string `json:"field,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Pointer  string `json:"pointer,omitempty"`
+}
+
+// Severities in increasing order. A rule with severity 'off' is not run.
+var severities = []string{"off", "info", "warning", "error"}
+
+func severityLevel(severity string) int {
+	for i, s := range severities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+// ValidateSeverity returns an error if the severity is not one of off, info, warning or error
+func ValidateSeverity(severity string) error {
+	if severityLevel(severity) < 0 {
+		return fmt.Errorf("unknown severity '%s', use one of %v", severity, severities)
+	}
+	return nil
+}
+
+// LintFailed returns true if any of the findings has the given severity or higher. It never fails on 'off'.
+func LintFailed(findings []*LintFinding, failOn string) bool {
+	if failOn == "off" {
+		return false
+	}
+	for _, finding := range findings {
+		if severityLevel(finding.Severity) >= severityLevel(failOn) {
+			return true
+		}
+	}
+	return false
+}
+
+// LintFailOn returns the severity threshold for the linter from the 'lint.<linter>.fail-on' config property.
+// Error is used if not configured.
+func LintFailOn(linter string) string {
+	if failOn := viper.GetString("lint." + linter + ".fail-on"); failOn != "" {
+		return failOn
+	}
+	return "error"
+}
+
+// applyRuleSeverities sets the severity of each finding from the 'lint.<linter>.rules' config property,
+// falling back on the default severity of the rule. Findings of rules that are turned off are removed. Exits if
+// the config property has unknown rules or severities.
+func applyRuleSeverities(linter string, findings []*LintFinding, defaults map[string]string) []*LintFinding {
+	ruleSeverities, err := configuredRuleSeverities(linter, defaults)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	result := []*LintFinding{}
+	for _, finding := range findings {
+		severity := ruleSeverities[finding.Rule]
+		if severity == "off" {
+			continue
+		}
+		finding.Severity = severity
+		result = append(result, finding)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return severityLevel(result[i].Severity) > severityLevel(result[j].Severity)
+	})
+	return result
+}
+
+// configuredRuleSeverities returns the severity of each rule of the linter, from the 'lint.<linter>.rules' config
+// property or the default severity of the rule. An error is returned for unknown rules and severities, since a
+// misspelled severity would otherwise never fail the lint.
+func configuredRuleSeverities(linter string, defaults map[string]string) (map[string]string, error) {
+	key := "lint." + linter + ".rules"
+	configured := viper.GetStringMapString(key)
+	result := map[string]string{}
+	for rule, severity := range defaults {
+		result[rule] = severity
+	}
+	rules := []string{}
+	for rule := range configured {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		if _, known := defaults[rule]; !known {
+			return nil, fmt.Errorf("unknown rule '%s' in %s, use one of %s", rule, key, strings.Join(sortedKeys(defaults), ", "))
+		}
+		if err := ValidateSeverity(configured[rule]); err != nil {
+			return nil, fmt.Errorf("invalid severity of rule '%s' in %s: %s", rule, key, err)
+		}
+		result[rule] = configured[rule]
+	}
+	return result, nil
+}
+
+// lintSetting returns the numeric setting 'lint.<linter>.<name>' or the default value if not configured
+func lintSetting(linter, name string, defaultValue float64) float64 {
+	key := "lint." + linter + "." + name
+	if viper.IsSet(key) {
+		return viper.GetFloat64(key)
+	}
+	return defaultValue
+}
@@ -0,0 +1,172 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Default severities of the data model rules
+var modelLintRules = map[string]string{
+	"synthetic-keys":      "error",
+	"circular-references": "error",
+	"low-key-overlap":     "warning",
+	"high-cardinality":    "info",
+	"unassociated-tables": "warning",
+	"timestamps-as-text":  "warning",
+}
+
+// Matches sample content like 2019-06-12, 2019-06-12 13:37:00 or 12/06/2019
+var timestampLike = regexp.MustCompile(`^(\d{4}-\d{1,2}-\d{1,2}([ T]\d{1,2}:\d{2}(:\d{2})?)?|\d{1,2}[/.]\d{1,2}[/.]\d{2,4})`)
+
+// LintModel checks the data model for common problems. The severity of each rule and the
+// thresholds used can be configured in the 'lint.model' section of the config file.
+func LintModel(data *ModelMetadata) []*LintFinding {
+	minKeyOverlap := lintSetting("model", "min-key-overlap", 0.5)
+	maxCardinalityRatio := lintSetting("model", "max-cardinality-ratio", 0.9)
+	minRows := int(lintSetting("model", "min-rows", 1000))
+
+	findings := []*LintFinding{}
+	findings = append(findings, lintSyntheticKeys(data)...)
+	findings = append(findings, lintCircularReferences(data)...)
+	findings = append(findings, lintKeyOverlap(data, minKeyOverlap)...)
+	findings = append(findings, lintHighCardinality(data, maxCardinalityRatio, minRows)...)
+	findings = append(findings, lintUnassociatedTables(data)...)
+	findings = append(findings, lintTimestampsAsText(data)...)
+	return applyRuleSeverities("model", findings, modelLintRules)
+}
+
+func lintSyntheticKeys(data *ModelMetadata) []*LintFinding {
+	findings := []*LintFinding{}
+	for _, table := range data.Tables {
+		if table.IsSynthetic || strings.HasPrefix(table.Name, "$Syn") {
+			findings = append(findings, &LintFinding{
+				Rule:    "synthetic-keys",
+				Table:   table.Name,
+				Message: fmt.Sprintf("synthetic key table linking the fields %s", data.FieldsInTableTexts[table.Name]),
+			})
+		}
+	}
+	return findings
+}
+
+func lintCircularReferences(data *ModelMetadata) []*LintFinding {
+	findings := []*LintFinding{}
+	for _, table := range data.Tables {
+		if table.Loose {
+			findings = append(findings, &LintFinding{
+				Rule:    "circular-references",
+				Table:   table.Name,
+				Message: "table is loosely coupled because of a circular reference",
+			})
+		}
+	}
+	return findings
+}
+
+// lintKeyOverlap estimates the share of the distinct key values present in both tables of each
+// pair of tables sharing the key, using the subset ratios: overlap = ratioA + ratioB - 1.
+func lintKeyOverlap(data *ModelMetadata, minOverlap float64) []*LintFinding {
+	findings := []*LintFinding{}
+	for _, field := range data.Fields {
+		if field.FieldDescription == nil || !isKey(field) {
+			continue
+		}
+		for i, a := range field.FieldInTable {
+			for j := i + 1; j < len(field.FieldInTable); j++ {
+				b := field.FieldInTable[j]
+				if a == nil || b == nil {
+					continue
+				}
+				overlap := float64(a.SubsetRatio) + float64(b.SubsetRatio) - 1
+				if overlap < minOverlap {
+					findings = append(findings, &LintFinding{
+						Rule:  "low-key-overlap",
+						Field: field.Name,
+						Message: fmt.Sprintf("only %.0f%% of the key values are present in both '%s' and '%s'",
+							maxFloat(overlap, 0)*100, data.Tables[i].Name, data.Tables[j].Name),
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func lintHighCardinality(data *ModelMetadata, maxRatio float64, minRows int) []*LintFinding {
+	findings := []*LintFinding{}
+	for _, field := range data.Fields {
+		if field.FieldDescription == nil || field.IsSystem || isKey(field) || field.TotalCount < minRows {
+			continue
+		}
+		ratio := float64(field.Cardinal) / float64(field.TotalCount)
+		if ratio >= maxRatio {
+			findings = append(findings, &LintFinding{
+				Rule:  "high-cardinality",
+				Field: field.Name,
+				Message: fmt.Sprintf("%d distinct values in %d rows (%s), consider splitting or dropping the field",
+					field.Cardinal, field.TotalCount, field.MemUsage()),
+			})
+		}
+	}
+	return findings
+}
+
+func lintUnassociatedTables(data *ModelMetadata) []*LintFinding {
+	findings := []*LintFinding{}
+	if len(data.Tables) < 2 {
+		return findings
+	}
+	for _, table := range data.Tables {
+		associated := false
+		for _, field := range table.Fields {
+			if field.KeyType != "" && field.KeyType != "NOT_KEY" {
+				associated = true
+			}
+		}
+		if !associated {
+			findings = append(findings, &LintFinding{
+				Rule:    "unassociated-tables",
+				Table:   table.Name,
+				Message: "table is not associated with any other table",
+			})
+		}
+	}
+	return findings
+}
+
+func lintTimestampsAsText(data *ModelMetadata) []*LintFinding {
+	findings := []*LintFinding{}
+	for _, field := range data.Fields {
+		if field.FieldDescription == nil || field.IsSystem || field.IsNumeric || !hasTag(field.Tags, "$text") {
+			continue
+		}
+		if hasTag(field.Tags, "$date") || hasTag(field.Tags, "$timestamp") {
+			continue
+		}
+		if sample := data.SampleContentByFieldName[field.Name]; timestampLike.MatchString(sample) {
+			findings = append(findings, &LintFinding{
+				Rule:    "timestamps-as-text",
+				Field:   field.Name,
+				Message: fmt.Sprintf("values like '%s' are stored as text, use Date#() or Timestamp#() in the script", strings.Split(sample, ",")[0]),
+			})
+		}
+	}
+	return findings
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/qlik-oss/enigma-go"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintModel(t *testing.T) {
+	orders := &TableModel{TableRecord: &enigma.TableRecord{Name: "Orders", Fields: []*enigma.FieldInTableData{
+		{Name: "CustomerId", KeyType: "ANY_KEY", SubsetRatio: 0.6},
+		{Name: "OrderDate"},
+	}}}
+	customers := &TableModel{TableRecord: &enigma.TableRecord{Name: "Customers", Fields: []*enigma.FieldInTableData{
+		{Name: "CustomerId", KeyType: "PRIMARY_KEY", SubsetRatio: 0.5},
+	}}}
+	synthetic := &TableModel{TableRecord: &enigma.TableRecord{Name: "$Syn 1 Table", IsSynthetic: true}}
+	loose := &TableModel{TableRecord: &enigma.TableRecord{Name: "Loose", Loose: true, Fields: []*enigma.FieldInTableData{
+		{Name: "Comment"},
+	}}}
+	customerID := &FieldModel{
+		FieldDescription: &enigma.FieldDescription{Name: "CustomerId", Tags: []string{"$key"}},
+		FieldInTable:     []*enigma.FieldInTableData{orders.Fields[0], customers.Fields[0], nil, nil},
+	}
+	orderDate := &FieldModel{FieldDescription: &enigma.FieldDescription{Name: "OrderDate", Tags: []string{"$ascii", "$text"}}}
+	comment := &FieldModel{FieldDescription: &enigma.FieldDescription{Name: "Comment", Cardinal: 1990, TotalCount: 2000}}
+	data := &ModelMetadata{
+		Tables:                   []*TableModel{orders, customers, synthetic, loose},
+		Fields:                   []*FieldModel{customerID, orderDate, comment},
+		SampleContentByFieldName: map[string]string{"OrderDate": "2019-06-12, 2019-06-13"},
+	}
+
+	rules := func(findings []*LintFinding) []string {
+		result := []string{}
+		for _, finding := range findings {
+			result = append(result, finding.Severity+" "+finding.Rule+" "+lintLocationForTest(finding))
+		}
+		return result
+	}
+
+	findings := LintModel(data)
+	assert.Equal(t, []string{
+		"error synthetic-keys $Syn 1 Table",
+		"error circular-references Loose",
+		"warning low-key-overlap CustomerId",
+		"warning unassociated-tables $Syn 1 Table",
+		"warning unassociated-tables Loose",
+		"warning timestamps-as-text OrderDate",
+		"info high-cardinality Comment",
+	}, rules(findings))
+	assert.True(t, LintFailed(findings, "error"))
+
+	viper.Set("lint.model.rules", map[string]string{"synthetic-keys": "off", "circular-references": "info"})
+	viper.Set("lint.model.min-key-overlap", 0.1)
+	defer viper.Set("lint.model", nil)
+	findings = LintModel(data)
+	assert.NotContains(t, rules(findings), "error synthetic-keys $Syn 1 Table")
+	assert.Contains(t, rules(findings), "info circular-references Loose")
+	assert.NotContains(t, rules(findings), "warning low-key-overlap CustomerId")
+	assert.False(t, LintFailed(findings, "error"))
+}
+
+func lintLocationForTest(finding *LintFinding) string {
+	if finding.Table != "" {
+		return finding.Table
+	}
+	return finding.Field
+}
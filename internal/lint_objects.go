@@ -0,0 +1,216 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/qlik-oss/enigma-go"
+)
+
+type (
+	// objectLinter collects findings and the state needed for the checks spanning several files
+	objectLinter struct {
+		findings    []*LintFinding
+		ids         map[string]string
+		masterItems map[string]bool
+		references  []libraryReference
+	}
+
+	// libraryReference is a qLibraryId found in an entity file
+	libraryReference struct {
+		id      string
+		path    string
+		pointer string
+	}
+)
+
+// Default severities of the object rules
+var objectLintRules = map[string]string{
+	"schema":             "error",
+	"unknown-library-id": "error",
+	"duplicate-id":       "error",
+}
+
+// The kinds of entity files, master items first so that references to them can be resolved
+var entityKinds = []string{"measures", "dimensions", "variables", "bookmarks", "objects"}
+
+// Types with a schema of their own, other objects are only validated against the generic object schema
+var typedSchemas = map[string]bool{
+	"sheet": true, "table": true, "pivot-table": true, "barchart": true, "linechart": true, "piechart": true,
+	"combochart": true, "scatterplot": true, "treemap": true, "kpi": true, "listbox": true,
+}
+
+// LintObjects validates the entity files matching the glob pattern of each kind of entity (objects, measures,
+// dimensions, variables and bookmarks), falling back on the patterns in the config file. Each entity is validated
+// against the bundled JSON schema of its qType or visualization. References to master items that are neither in
+// the files nor among the given library IDs and qIds used more than once are reported as well.
+func LintObjects(globPatterns map[string]string, libraryIDs []string) []*LintFinding {
+	files := map[string][]string{}
+	for _, kind := range entityKinds {
+		paths, err := findEntityPaths(globPatterns[kind], kind, false)
+		if err != nil {
+			log.Fatalln("could not interpret glob pattern: ", err)
+		}
+		files[kind] = paths
+	}
+	return applyRuleSeverities("objects", lintEntityFiles(files, libraryIDs), objectLintRules)
+}
+
+// PreflightObjects lints the entity files before they are added to the app and exits if there are any errors.
+// Master items already in the app are valid qLibraryId references. Warnings are logged as well, and findings can
+// be downgraded with the rule severities in the 'lint.objects.rules' config property.
+func PreflightObjects(ctx context.Context, doc *enigma.Doc, globPatterns map[string]string) {
+	libraryIDs := []string{}
+	for _, measure := range ListMeasures(ctx, doc) {
+		libraryIDs = append(libraryIDs, measure.ID)
+	}
+	for _, dimension := range ListDimensions(ctx, doc) {
+		libraryIDs = append(libraryIDs, dimension.ID)
+	}
+	failed := false
+	for _, finding := range LintObjects(globPatterns, libraryIDs) {
+		switch finding.Severity {
+		case "error":
+			log.Errorf("%s#%s: %s\n", finding.File, finding.Pointer, finding.Message)
+			failed = true
+		case "warning":
+			log.Warnf("%s#%s: %s\n", finding.File, finding.Pointer, finding.Message)
+		default:
+			log.Verbosef("%s#%s: %s\n", finding.File, finding.Pointer, finding.Message)
+		}
+	}
+	if failed {
+		log.Fatalln("One or more entities are invalid, see 'corectl lint objects' for details")
+	}
+}
+
+func lintEntityFiles(files map[string][]string, libraryIDs []string) []*LintFinding {
+	linter := &objectLinter{
+		findings:    []*LintFinding{},
+		ids:         map[string]string{},
+		masterItems: map[string]bool{},
+	}
+	for _, id := range libraryIDs {
+		linter.masterItems[id] = true
+	}
+	for _, kind := range entityKinds {
+		for _, path := range files[kind] {
+			linter.lintFile(kind, path)
+		}
+	}
+	for _, reference := range linter.references {
+		if !linter.masterItems[reference.id] {
+			linter.add("unknown-library-id", reference.path, reference.pointer, "qLibraryId '%s' does not match any master measure or dimension", reference.id)
+		}
+	}
+	return linter.findings
+}
+
+func (l *objectLinter) lintFile(kind, path string) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		l.add("schema", path, "", "could not read file: %s", err)
+		return
+	}
+	var value interface{}
+	if err := json.Unmarshal(content, &value); err != nil {
+		l.add("schema", path, "", "could not parse file: %s", err)
+		return
+	}
+	if entities, ok := value.([]interface{}); ok {
+		for i, entity := range entities {
+			l.lintEntity(kind, path, "/"+strconv.Itoa(i), entity)
+		}
+	} else {
+		l.lintEntity(kind, path, "", value)
+	}
+}
+
+// lintEntity validates the entity, which is either the properties or a full property tree with qProperty and qChildren
+func (l *objectLinter) lintEntity(kind, path, pointer string, entity interface{}) {
+	tree, _ := entity.(map[string]interface{})
+	if properties, ok := tree["qProperty"]; ok {
+		l.lintProperties(kind, path, pointer+"/qProperty", properties)
+		children, _ := tree["qChildren"].([]interface{})
+		for i, child := range children {
+			l.lintEntity(kind, path, pointer+"/qChildren/"+strconv.Itoa(i), child)
+		}
+		return
+	}
+	l.lintProperties(kind, path, pointer, entity)
+}
+
+func (l *objectLinter) lintProperties(kind, path, pointer string, properties interface{}) {
+	for _, err := range bundledSchemas.validate(schemaFor(kind, properties), properties, pointer) {
+		l.add("schema", path, err.Pointer, "%s", err.Message)
+	}
+	props, _ := properties.(map[string]interface{})
+	info, _ := props["qInfo"].(map[string]interface{})
+	if id, ok := info["qId"].(string); ok && id != "" {
+		if previous, exists := l.ids[id]; exists {
+			l.add("duplicate-id", path, pointer+"/qInfo/qId", "qId '%s' is also used in %s", id, previous)
+		} else {
+			l.ids[id] = path + "#" + pointer
+		}
+		if kind == "measures" || kind == "dimensions" {
+			l.masterItems[id] = true
+		}
+	}
+	l.collectReferences(path, pointer, properties)
+}
+
+// collectReferences stores the qLibraryId references until all master items are known
+func (l *objectLinter) collectReferences(path, pointer string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := []string{}
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := v[key]
+			if id, ok := child.(string); ok && key == "qLibraryId" && id != "" {
+				l.references = append(l.references, libraryReference{id: id, path: path, pointer: pointer + "/qLibraryId"})
+			} else {
+				l.collectReferences(path, pointer+"/"+escapePointer(key), child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			l.collectReferences(path, pointer+"/"+strconv.Itoa(i), child)
+		}
+	}
+}
+
+func (l *objectLinter) add(rule, path, pointer, format string, a ...interface{}) {
+	l.findings = append(l.findings, &LintFinding{Rule: rule, File: path, Pointer: pointer, Message: fmt.Sprintf(format, a...)})
+}
+
+// schemaFor returns the schema of the entity based on the kind of file it is in and its type
+func schemaFor(kind string, properties interface{}) *jsonSchema {
+	switch kind {
+	case "measures":
+		return bundledSchemas["measure"]
+	case "dimensions":
+		return bundledSchemas["dimension"]
+	case "variables":
+		return bundledSchemas["variable"]
+	case "bookmarks":
+		return bundledSchemas["bookmark"]
+	}
+	props, _ := properties.(map[string]interface{})
+	if visualization, ok := props["visualization"].(string); ok && typedSchemas[visualization] {
+		return bundledSchemas[visualization]
+	}
+	info, _ := props["qInfo"].(map[string]interface{})
+	if qType, ok := info["qType"].(string); ok && typedSchemas[qType] {
+		return bundledSchemas[qType]
+	}
+	return bundledSchemas["generic"]
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintEntityFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "corectl-lint-objects")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	measures := write("measures.json", `[
+		{"qInfo": {"qId": "measure-sales", "qType": "measure"}, "qMeasure": {"qDef": "Sum(Sales)"}},
+		{"qInfo": {"qId": "measure-empty", "qType": "measure"}, "qMeasure": {"qDef": ""}}
+	]`)
+	objects := write("objects.json", `[
+		{"qInfo": {"qId": "kpi", "qType": "kpi"}, "visualization": "kpi", "qHyperCubeDef": {
+			"qMeasures": [{"qLibraryId": "measure-sales"}, {"qLibraryId": "measure-missing"}],
+			"qInitialDataFetch": [{"qWidth": "10"}]
+		}},
+		{"qInfo": {"qId": "measure-sales", "qType": "listbox"}}
+	]`)
+	sheet := write("sheet.json", `{
+		"qProperty": {"qInfo": {"qId": "sheet", "qType": "sheet"}, "cells": [{"name": "kpi", "type": "kpi", "col": -1}]},
+		"qChildren": [{"qProperty": {"qInfo": {"qType": "table"}, "qHyperCubeDef": {}}}]
+	}`)
+
+	findings := lintEntityFiles(map[string][]string{"measures": {measures}, "objects": {objects, sheet}}, nil)
+	result := []string{}
+	for _, finding := range findings {
+		result = append(result, finding.Rule+" "+filepath.Base(finding.File)+"#"+finding.Pointer+" "+finding.Message)
+	}
+	assert.Equal(t, []string{
+		"schema measures.json#/1/qMeasure/qDef expected a string of at least 1 character(s)",
+		"schema objects.json#/0/qHyperCubeDef/qInitialDataFetch/0/qWidth expected integer but found string",
+		"schema objects.json#/1 missing required property 'qListObjectDef'",
+		"duplicate-id objects.json#/1/qInfo/qId qId 'measure-sales' is also used in " + measures + "#/0",
+		"schema sheet.json#/qProperty/cells/0/col expected a number of at least 0 but found -1",
+		"schema sheet.json#/qChildren/0/qProperty/qInfo missing required property 'qId'",
+		"unknown-library-id objects.json#/0/qHyperCubeDef/qMeasures/1/qLibraryId qLibraryId 'measure-missing' does not match any master measure or dimension",
+	}, result)
+}
+
+func TestLintLinkedVisualizations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "corectl-lint-objects")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	objects := filepath.Join(dir, "objects.json")
+	assert.NoError(t, ioutil.WriteFile(objects, []byte(`[
+		{"qInfo": {"qId": "bc1", "qType": "barchart"}, "qExtendsId": "masterViz1"},
+		{"qInfo": {"qId": "lb1", "qType": "listbox"}, "qExtendsId": "masterViz2"},
+		{"qInfo": {"qId": "bc2", "qType": "barchart"}}
+	]`), 0644))
+
+	findings := lintEntityFiles(map[string][]string{"objects": {objects}}, nil)
+	assert.Len(t, findings, 1)
+	assert.Equal(t, "/2", findings[0].Pointer)
+	assert.Equal(t, "missing required property 'qHyperCubeDef'", findings[0].Message)
+}
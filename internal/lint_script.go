@@ -0,0 +1,381 @@
+package internal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/spf13/viper"
+)
+
+type (
+	// scriptStatement is one statement in a load script along with the line it starts on
+	scriptStatement struct {
+		text string
+		line int
+	}
+
+	// loadStatement is a LOAD or SELECT statement, including any preceding loads
+	loadStatement struct {
+		line     int
+		label    string
+		prefixes []string
+		fields   []string
+		loadStar bool
+		from     string
+		resident string
+	}
+)
+
+// Default severities of the script rules
+var scriptLintRules = map[string]string{
+	"load-star":          "warning",
+	"missing-drop":       "warning",
+	"auto-concatenate":   "warning",
+	"unknown-connection": "error",
+	"set-let-misuse":     "warning",
+}
+
+// Control statements are terminated by the end of the line as well as by semicolon
+var controlStatements = map[string]bool{
+	"if": true, "elseif": true, "else": true, "end": true, "for": true, "next": true, "do": true,
+	"loop": true, "sub": true, "switch": true, "case": true, "default": true, "exit": true, "call": true,
+}
+
+var (
+	labelRegexp      = regexp.MustCompile(`^(?s)\s*(\[[^\]]+\]|"[^"]+"|[\pL\d_.$#@%]+)\s*:\s*(.*)$`)
+	prefixRegexp     = regexp.MustCompile(`^(?is)\s*(concatenate|noconcatenate|mapping|join|left|right|inner|outer|keep|add|replace|only|buffer|first\s+\d+)\b\s*(\([^)]*\))?\s*(.*)$`)
+	loadRegexp       = regexp.MustCompile(`^(?is)\s*(load|sql\s+select|select)\b\s*(distinct\s+)?(.*)$`)
+	sourceRegexp     = regexp.MustCompile(`(?is)\b(from|resident|inline|autogenerate|extension)\b`)
+	residentRegexp   = regexp.MustCompile(`(?is)\bresident\s+(\[[^\]]+\]|"[^"]+"|[^\s;]+)`)
+	fromRegexp       = regexp.MustCompile(`(?is)\bfrom\s+(\[[^\]]+\]|'[^']+'|"[^"]+"|[^\s;(]+)`)
+	libRegexp        = regexp.MustCompile(`(?i)lib://([^/\]'"\s)]+)`)
+	dropRegexp       = regexp.MustCompile(`(?is)^\s*drop\s+tables?\s+(.*)$`)
+	variableRegexp   = regexp.MustCompile(`(?is)^\s*(set|let)\s+(\[[^\]]+\]|[^\s=]+)\s*=\s*(.*)$`)
+	scriptFunctions  = regexp.MustCompile(`(?i)\b(today|now|date|year|month|monthstart|monthend|addmonths|yearstart|makedate|num|peek|noofrows|fieldvalue|filetime|reloadtime)\s*\(`)
+	aggregations     = regexp.MustCompile(`(?i)\b(sum|count|avg|only|concat|aggr)\s*\(`)
+	asRegexp         = regexp.MustCompile(`(?is)^(.*)\s+as\s+(.+)$`)
+	defaultTempTable = `^(?i)(tmp|temp)`
+)
+
+// LintScript parses the script file and checks it for common problems. Rules can be turned off
+// and the severity changed in the 'lint.script' section of the config file. Connections defined in the
+// connections config are used to verify lib:// paths.
+func LintScript(scriptFile string, connections *ConnectionsConfig) []*LintFinding {
+	script, err := ioutil.ReadFile(scriptFile)
+	if err != nil {
+		log.Fatalf("could not find loadscript: %s\n", scriptFile)
+	}
+	tempTablePattern := viper.GetString("lint.script.temp-table-pattern")
+	if tempTablePattern == "" {
+		tempTablePattern = defaultTempTable
+	}
+	tempTable, err := regexp.Compile(tempTablePattern)
+	if err != nil {
+		log.Fatalf("invalid temp-table-pattern '%s': %s\n", tempTablePattern, err)
+	}
+	findings := lintScript(string(script), connections, tempTable)
+	for _, finding := range findings {
+		finding.File = scriptFile
+	}
+	return applyRuleSeverities("script", findings, scriptLintRules)
+}
+
+func lintScript(script string, connections *ConnectionsConfig, tempTable *regexp.Regexp) []*LintFinding {
+	findings := []*LintFinding{}
+	add := func(rule string, line int, format string, a ...interface{}) {
+		findings = append(findings, &LintFinding{Rule: rule, Line: line, Message: fmt.Sprintf(format, a...)})
+	}
+
+	loads := []*loadStatement{}
+	dropped := map[string]bool{}
+	var preceding *loadStatement
+	for _, statement := range splitScript(script) {
+		if match := dropRegexp.FindStringSubmatch(statement.text); match != nil {
+			for _, table := range splitTopLevel(match[1]) {
+				dropped[unquoteName(table)] = true
+			}
+			continue
+		}
+		if match := variableRegexp.FindStringSubmatch(statement.text); match != nil {
+			lintVariable(strings.ToLower(match[1]), unquoteName(match[2]), strings.TrimSpace(match[3]), statement.line, add)
+			continue
+		}
+		load := parseLoadStatement(statement)
+		if load == nil {
+			continue
+		}
+		if preceding != nil {
+			// The preceding load defines the label, prefixes and resulting fields of the table
+			load.line, load.label, load.prefixes = preceding.line, preceding.label, preceding.prefixes
+			load.fields, load.loadStar = preceding.fields, preceding.loadStar
+			preceding = nil
+		}
+		if load.from == "" && load.resident == "" && !sourceRegexp.MatchString(statement.text) {
+			preceding = load
+			continue
+		}
+		loads = append(loads, load)
+	}
+
+	for i, load := range loads {
+		if load.loadStar && load.from != "" {
+			add("load-star", load.line, "LOAD * from %s loads all fields, list the fields needed explicitly", load.from)
+		}
+		if connections != nil && connections.Connections != nil {
+			for _, match := range libRegexp.FindAllStringSubmatch(load.from, -1) {
+				if _, ok := (*connections.Connections)[match[1]]; !ok {
+					add("unknown-connection", load.line, "lib://%s does not match any connection in the connections config", match[1])
+				}
+			}
+		}
+		if !hasPrefix(load, "concatenate", "noconcatenate", "join", "keep", "mapping") && !load.loadStar && len(load.fields) > 0 {
+			for _, earlier := range loads[:i] {
+				if earlier.label != load.label && !earlier.loadStar && !hasPrefix(earlier, "mapping", "join", "keep") &&
+					!dropped[earlier.label] && sameFields(earlier.fields, load.fields) {
+					add("auto-concatenate", load.line, "the table%s has the same fields as the table%s loaded on line %d and is automatically concatenated to it, use Concatenate or NoConcatenate",
+						nameSuffix(load.label), nameSuffix(earlier.label), earlier.line)
+					break
+				}
+			}
+		}
+	}
+
+	reported := map[string]bool{}
+	for _, load := range loads {
+		if load.label == "" || reported[load.label] || hasPrefix(load, "mapping") {
+			continue
+		}
+		if tempTable.MatchString(load.label) && !dropped[load.label] {
+			add("missing-drop", load.line, "the temporary table '%s' is never dropped", load.label)
+			reported[load.label] = true
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+func lintVariable(kind, name, value string, line int, add func(string, int, string, ...interface{})) {
+	switch {
+	case kind == "set" && scriptFunctions.MatchString(value) && !aggregations.MatchString(value):
+		add("set-let-misuse", line, "SET stores '%s' as text in %s without evaluating it, use LET to store the result", value, name)
+	case kind == "let" && (aggregations.MatchString(value) || strings.HasPrefix(value, "'=")):
+		add("set-let-misuse", line, "LET evaluates '%s' in the script, use SET to store the expression in %s", value, name)
+	}
+}
+
+func parseLoadStatement(statement scriptStatement) *loadStatement {
+	load := &loadStatement{line: statement.line}
+	text := statement.text
+	if match := labelRegexp.FindStringSubmatch(text); match != nil && !strings.HasPrefix(strings.ToLower(strings.TrimSpace(match[1])), "lib") {
+		load.label = unquoteName(match[1])
+		text = match[2]
+	}
+	for {
+		match := prefixRegexp.FindStringSubmatch(text)
+		if match == nil {
+			break
+		}
+		load.prefixes = append(load.prefixes, strings.ToLower(strings.Fields(match[1])[0]))
+		text = match[3]
+	}
+	match := loadRegexp.FindStringSubmatch(text)
+	if match == nil {
+		return nil
+	}
+	body := match[3]
+	fieldList := body
+	if location := sourceRegexp.FindStringIndex(body); location != nil {
+		fieldList = body[:location[0]]
+	}
+	for _, field := range splitTopLevel(fieldList) {
+		if field == "*" {
+			load.loadStar = true
+			continue
+		}
+		if as := asRegexp.FindStringSubmatch(field); as != nil {
+			field = as[2]
+		}
+		load.fields = append(load.fields, unquoteName(field))
+	}
+	if resident := residentRegexp.FindStringSubmatch(body); resident != nil {
+		load.resident = unquoteName(resident[1])
+	} else if from := fromRegexp.FindStringSubmatch(body); from != nil {
+		load.from = strings.Trim(from[1], `[]'"`)
+	}
+	return load
+}
+
+// splitScript splits the script into statements, skipping comments
+func splitScript(script string) []scriptStatement {
+	statements := []scriptStatement{}
+	runes := []rune(script)
+	n := len(runes)
+	line := 1
+	start := 0
+	var current strings.Builder
+
+	flush := func() {
+		if text := strings.TrimSpace(current.String()); text != "" {
+			statements = append(statements, scriptStatement{text: text, line: start})
+		}
+		current.Reset()
+		start = 0
+	}
+	write := func(r rune) {
+		if start == 0 && !isSpace(r) {
+			start = line
+		}
+		current.WriteRune(r)
+	}
+	isControl := func() bool {
+		words := strings.Fields(current.String())
+		return len(words) > 0 && controlStatements[strings.ToLower(words[0])]
+	}
+
+	for i := 0; i < n; i++ {
+		r := runes[i]
+		switch {
+		case r == '\n':
+			line++
+			if isControl() {
+				flush()
+			} else {
+				current.WriteRune(r)
+			}
+		case r == ';':
+			flush()
+		case r == '/' && i+1 < n && runes[i+1] == '/' && (i == 0 || runes[i-1] != ':'):
+			for i+1 < n && runes[i+1] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < n && runes[i+1] == '*':
+			for i += 2; i < n && !(runes[i] == '*' && i+1 < n && runes[i+1] == '/'); i++ {
+				if runes[i] == '\n' {
+					line++
+				}
+			}
+			i++
+		case r == '\'' || r == '"' || r == '[' || r == '`':
+			closing := r
+			if r == '[' {
+				closing = ']'
+			}
+			write(r)
+			for i++; i < n && runes[i] != closing; i++ {
+				if runes[i] == '\n' {
+					line++
+				}
+				current.WriteRune(runes[i])
+			}
+			if i < n {
+				current.WriteRune(runes[i])
+			}
+		default:
+			if strings.TrimSpace(current.String()) == "" && strings.EqualFold(string(runes[i:minInt(i+3, n)]), "rem") && i+3 < n && isSpace(runes[i+3]) {
+				// REM comments run until the next semicolon
+				for i < n && runes[i] != ';' {
+					if runes[i] == '\n' {
+						line++
+					}
+					i++
+				}
+				current.Reset()
+				start = 0
+				continue
+			}
+			write(r)
+		}
+	}
+	flush()
+	return statements
+}
+
+// splitTopLevel splits a comma separated list, ignoring commas inside parentheses and quotes
+func splitTopLevel(list string) []string {
+	result := []string{}
+	depth := 0
+	var quote rune
+	var current strings.Builder
+	for _, r := range list {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '[':
+			quote = ']'
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			if item := strings.TrimSpace(current.String()); item != "" {
+				result = append(result, item)
+			}
+			current.Reset()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	if item := strings.TrimSpace(current.String()); item != "" {
+		result = append(result, item)
+	}
+	return result
+}
+
+func unquoteName(name string) string {
+	name = strings.TrimSpace(name)
+	if len(name) >= 2 {
+		first, last := name[0], name[len(name)-1]
+		if first == '[' && last == ']' || first == '"' && last == '"' || first == '`' && last == '`' {
+			return name[1 : len(name)-1]
+		}
+	}
+	return name
+}
+
+func hasPrefix(load *loadStatement, prefixes ...string) bool {
+	for _, prefix := range load.prefixes {
+		for _, p := range prefixes {
+			if prefix == p || p == "join" && (prefix == "left" || prefix == "right" || prefix == "inner" || prefix == "outer") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sameFields(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := map[string]bool{}
+	for _, field := range a {
+		set[field] = true
+	}
+	for _, field := range b {
+		if !set[field] {
+			return false
+		}
+	}
+	return true
+}
+
+func nameSuffix(name string) string {
+	if name == "" {
+		return ""
+	}
+	return " '" + name + "'"
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r' || r == '\n'
+}
@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const lintTestScript = `///$tab Main
+SET ThousandSep=',';
+SET TimeFormat='h:mm:ss';
+SET vToday = Today();
+LET vSales = Sum(Sales);
+
+// A comment with LOAD * FROM lib://Nowhere/x.csv;
+TmpOrders:
+LOAD * FROM [lib://Data/orders.csv] (txt);
+
+Orders:
+LOAD OrderId, Amount as Sales RESIDENT TmpOrders;
+
+/* Block comment
+   spanning lines; */
+OldOrders:
+LOAD OrderId, Sales FROM 'lib://Archive/orders.qvd' (qvd);
+
+IF 1 = 1 THEN
+TempCustomers:
+NoConcatenate LOAD CustomerId, Name;
+SQL SELECT CustomerId, Name FROM Customers;
+END IF
+
+REM LOAD * FROM lib://Missing/x.csv;
+DROP TABLE TempCustomers;
+`
+
+func TestLintScript(t *testing.T) {
+	connections := &ConnectionsConfig{Connections: &map[string]ConnectionConfigEntry{"Data": {Type: "folder"}}}
+	findings := lintScript(lintTestScript, connections, regexp.MustCompile(defaultTempTable))
+	result := []string{}
+	for _, finding := range findings {
+		result = append(result, fmt.Sprintf("%d %s", finding.Line, finding.Rule))
+	}
+	assert.Equal(t, []string{
+		"4 set-let-misuse",
+		"5 set-let-misuse",
+		"8 load-star",
+		"8 missing-drop",
+		"16 unknown-connection",
+		"16 auto-concatenate",
+	}, result)
+}
+
+func TestSplitScript(t *testing.T) {
+	statements := splitScript("LOAD 'a;b' as [c;d] FROM lib://x/y.csv; // done\nIF x THEN\n  LET a = 1;\nEND IF")
+	assert.Equal(t, []scriptStatement{
+		{text: "LOAD 'a;b' as [c;d] FROM lib://x/y.csv", line: 1},
+		{text: "IF x THEN", line: 2},
+		{text: "LET a = 1", line: 3},
+		{text: "END IF", line: 4},
+	}, statements)
+}
@@ -0,0 +1,36 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintFailed(t *testing.T) {
+	findings := []*LintFinding{{Rule: "high-cardinality", Severity: "info"}, {Rule: "synthetic-keys", Severity: "warning"}}
+	assert.False(t, LintFailed(findings, "error"))
+	assert.True(t, LintFailed(findings, "warning"))
+	assert.True(t, LintFailed(findings, "info"))
+	assert.False(t, LintFailed(findings, "off"))
+	assert.False(t, LintFailed(nil, "info"))
+}
+
+func TestConfiguredRuleSeverities(t *testing.T) {
+	defer viper.Set("lint.model.rules", nil)
+	viper.Set("lint.model.rules", map[string]interface{}{"synthetic-keys": "warning", "high-cardinality": "off"})
+	severities, err := configuredRuleSeverities("model", modelLintRules)
+	assert.Nil(t, err)
+	assert.Equal(t, "warning", severities["synthetic-keys"])
+	assert.Equal(t, "off", severities["high-cardinality"])
+	assert.Equal(t, "error", severities["circular-references"])
+
+	viper.Set("lint.model.rules", map[string]interface{}{"synthetic-keys": "warn"})
+	_, err = configuredRuleSeverities("model", modelLintRules)
+	assert.EqualError(t, err, "invalid severity of rule 'synthetic-keys' in lint.model.rules: unknown severity 'warn', use one of [off info warning error]")
+
+	viper.Set("lint.model.rules", map[string]interface{}{"synthetic-key": "error"})
+	_, err = configuredRuleSeverities("model", modelLintRules)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown rule 'synthetic-key' in lint.model.rules")
+}
@@ -32,6 +32,19 @@ func (l logLevel) String() string {
 	return ""
 }
 
+// name returns the name of the level used in JSON Lines
+func (l logLevel) name() string {
+	switch l {
+	case fatal, err:
+		return "error"
+	case warn:
+		return "warning"
+	case verbose:
+		return "verbose"
+	}
+	return "info"
+}
+
 const (
 	quiet logLevel = iota
 	fatal
@@ -83,6 +96,24 @@ var Traffic bool
 var buffering bool
 var buffer *logBuffer
 
+// JSONLinesWriter receives what is printed in json mode so that it can be written as lines of another JSON Lines
+// stream on stdout
+type JSONLinesWriter interface {
+	// Message receives a log message along with its level: error, warning, info or verbose
+	Message(level, message string)
+	// Data receives the data printed by PrintAsJSON
+	Data(data json.RawMessage)
+}
+
+// jsonLinesWriter is set when the output in json mode is JSON Lines
+var jsonLinesWriter JSONLinesWriter
+
+// SetJSONLinesWriter passes the messages and data printed in json mode to the writer instead of printing them as
+// indented JSON, nil restores the default.
+func SetJSONLinesWriter(writer JSONLinesWriter) {
+	jsonLinesWriter = writer
+}
+
 // Init reads the log-related viper flags json, verbose, traffic and quiet and sets the
 // internal (log.) level, printJSON and traffic variables accordingly.
 func Init() {
@@ -101,6 +132,13 @@ func Init() {
 	} else {
 		level = info
 	}
+	trafficOptions = &TrafficOptions{
+		Methods:  viper.GetStringSlice("traffic-methods"),
+		Handles:  viper.GetIntSlice("traffic-handles"),
+		Pretty:   viper.GetBool("traffic-pretty"),
+		Truncate: viper.GetInt("traffic-truncate"),
+		Timing:   viper.GetBool("traffic-timing"),
+	}
 	buffering = false
 	buffer.flush()
 }
@@ -125,17 +163,40 @@ func Quiet(a ...interface{}) {
 
 func Fatalf(format string, a ...interface{}) {
 	printf(fatal, format, a...)
-	os.Exit(1)
+	exit(fmt.Sprintf(format, a...))
 }
 
 func Fatalln(a ...interface{}) {
 	println(fatal, a...)
-	os.Exit(1)
+	exit(fmt.Sprintln(a...))
 }
 
 func Fatal(a ...interface{}) {
 	print(fatal, a...)
-	os.Exit(1)
+	exit(fmt.Sprint(a...))
+}
+
+// ExitCode returns the exit code used by the Fatal functions, it can be replaced to tell why the program failed
+var ExitCode = func() int {
+	return 1
+}
+
+// fatalHooks are run with the message of a fatal error before exiting
+var fatalHooks []func(message string)
+
+// OnFatal registers a function that is called with the message of a fatal error before the program exits.
+func OnFatal(hook func(message string)) {
+	fatalHooks = append(fatalHooks, hook)
+}
+
+func exit(message string) {
+	hooks := fatalHooks
+	// A hook that fails fatally should not run the hooks again
+	fatalHooks = nil
+	for _, hook := range hooks {
+		hook(strings.TrimSpace(message))
+	}
+	os.Exit(ExitCode())
 }
 
 func Errorln(a ...interface{}) {
@@ -202,7 +263,9 @@ func print(lvl logLevel, a ...interface{}) {
 		return
 	}
 	if printJSON {
-		if lvl != quiet {
+		if lvl != quiet && jsonLinesWriter != nil {
+			jsonLinesWriter.Message(lvl.name(), strings.TrimSpace(fmt.Sprint(a...)))
+		} else if lvl != quiet {
 			msg := map[string]string{
 				strings.ToLower(lvl.String()): fmt.Sprint(a...),
 			}
@@ -237,6 +300,10 @@ func PrintAsJSON(data interface{}) {
 	if err != nil {
 		Fatal(err)
 	}
+	if jsonLinesWriter != nil {
+		jsonLinesWriter.Data(jsonBytes)
+		return
+	}
 	var buffer bytes.Buffer
 	json.Indent(&buffer, jsonBytes, "", "  ")
 	fmt.Println(buffer.String())
@@ -1,24 +1,311 @@
 package log
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qlik-oss/enigma-go"
+	"github.com/spf13/viper"
 )
 
-// TrafficLogger is a struct implementing the TrafficLogger interface in enigma-go
-type TrafficLogger struct{}
+// TrafficOptions control which websocket traffic is logged and how
+type TrafficOptions struct {
+	// Methods and Handles limit the logged requests, and their responses, to the methods and handles
+	Methods []string
+	Handles []int
+	Pretty  bool
+	// Truncate limits the length of logged messages, 0 means no limit
+	Truncate int
+	// Timing adds the time between each request and its response to the logged response
+	Timing bool
+}
+
+var trafficOptions = &TrafficOptions{}
+
+// trafficOutput is where traffic is logged, stdout unless another output is set
+var trafficOutput io.Writer = os.Stdout
+var trafficMutex sync.Mutex
+
+// secretKeys are the lower case names of fields that are never logged or recorded
+var secretKeys = map[string]bool{
+	"qpassword":     true,
+	"password":      true,
+	"cookie":        true,
+	"set-cookie":    true,
+	"authorization": true,
+}
+
+const redacted = "***"
+
+// SetTrafficOutput logs the traffic to stderr or to the file at path instead of stdout. Since the traffic
+// then does not mix with the output of the command it is logged also when the output is json.
+func SetTrafficOutput(path string) {
+	if path == "stderr" {
+		trafficOutput = os.Stderr
+	} else {
+		file, err := os.Create(path)
+		if err != nil {
+			Fatalf("could not create traffic log '%s': %s\n", path, err)
+		}
+		trafficOutput = file
+	}
+	if printJSON {
+		Traffic = viper.GetBool("traffic")
+	}
+}
+
+// TrafficLogger is a struct implementing the TrafficLogger interface in enigma-go. It logs the traffic of
+// one connection.
+type TrafficLogger struct {
+	options *TrafficOptions
+	mutex   sync.Mutex
+	// pending are the requests sent but not yet responded to by id
+	pending map[string]*pendingRequest
+}
+
+type pendingRequest struct {
+	method string
+	handle int
+	sent   time.Time
+}
+
+// trafficMessage holds the fields of a JSON-RPC message used for filtering
+type trafficMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Handle int             `json:"handle"`
+}
+
+func newTrafficLogger(options *TrafficOptions) *TrafficLogger {
+	return &TrafficLogger{options: options, pending: map[string]*pendingRequest{}}
+}
+
+// Opened implements Opened() method in enigma-go TrafficLogger interface
+func (l *TrafficLogger) Opened() {}
+
+// Sent implements Sent() method in enigma-go TrafficLogger interface
+func (l *TrafficLogger) Sent(message []byte) {
+	request := &trafficMessage{}
+	json.Unmarshal(message, request)
+	l.mutex.Lock()
+	if len(request.ID) > 0 {
+		l.pending[string(request.ID)] = &pendingRequest{method: request.Method, handle: request.Handle, sent: time.Now()}
+	}
+	l.mutex.Unlock()
+	if l.include(request.Method, request.Handle) {
+		l.print("-->", "", message)
+	}
+}
+
+// Received implements Received() method in enigma-go TrafficLogger interface
+func (l *TrafficLogger) Received(message []byte) {
+	response := &trafficMessage{}
+	json.Unmarshal(message, response)
+	if len(response.ID) == 0 {
+		// Notifications are sent on the handle of the object they concern
+		if l.include(response.Method, response.Handle) {
+			l.print("<--", "", message)
+		}
+		return
+	}
+	l.mutex.Lock()
+	request := l.pending[string(response.ID)]
+	delete(l.pending, string(response.ID))
+	l.mutex.Unlock()
+	if request == nil {
+		request = &pendingRequest{}
+	}
+	if !l.include(request.method, request.handle) {
+		return
+	}
+	timing := ""
+	if l.options.Timing && !request.sent.IsZero() {
+		timing = fmt.Sprintf("(%s %s) ", request.method, time.Since(request.sent).Round(time.Microsecond))
+	}
+	l.print("<--", timing, message)
+}
+
+// Closed implements Closed() method in enigma-go TrafficLogger interface
+func (l *TrafficLogger) Closed() {}
+
+// include tells whether a message with the method and handle passes the filters
+func (l *TrafficLogger) include(method string, handle int) bool {
+	if len(l.options.Methods) > 0 {
+		found := false
+		for _, m := range l.options.Methods {
+			found = found || strings.EqualFold(m, method)
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(l.options.Handles) > 0 {
+		found := false
+		for _, h := range l.options.Handles {
+			found = found || h == handle
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *TrafficLogger) print(direction, prefix string, message []byte) {
+	text := l.format(message)
+	trafficMutex.Lock()
+	defer trafficMutex.Unlock()
+	fmt.Fprintln(trafficOutput, direction, prefix+text)
+}
+
+// format redacts, pretty prints and truncates the message according to the options
+func (l *TrafficLogger) format(message []byte) string {
+	message = RedactSecrets(message)
+	if l.options.Pretty {
+		buf := &bytes.Buffer{}
+		if json.Indent(buf, message, "", "  ") == nil {
+			message = buf.Bytes()
+		}
+	}
+	text := string(message)
+	if l.options.Truncate > 0 && len(text) > l.options.Truncate {
+		text = fmt.Sprintf("%s... (%d bytes)", text[:l.options.Truncate], len(text))
+	}
+	return text
+}
+
+// RedactSecrets replaces the values of fields like qPassword in a JSON message. Messages without
+// secrets are returned as they are.
+func RedactSecrets(message []byte) []byte {
+	lower := bytes.ToLower(message)
+	if !bytes.Contains(lower, []byte("password")) && !bytes.Contains(lower, []byte("cookie")) && !bytes.Contains(lower, []byte("authorization")) {
+		return message
+	}
+	var data interface{}
+	if err := json.Unmarshal(message, &data); err != nil {
+		return message
+	}
+	if !redact(data) {
+		return message
+	}
+	result, err := json.Marshal(data)
+	if err != nil {
+		return message
+	}
+	return result
+}
+
+// redact replaces secret values in place and tells whether any was found
+func redact(data interface{}) bool {
+	found := false
+	switch data := data.(type) {
+	case map[string]interface{}:
+		for key, value := range data {
+			if secretKeys[strings.ToLower(key)] {
+				if value != nil && value != "" {
+					data[key] = redacted
+					found = true
+				}
+			} else if redact(value) {
+				found = true
+			}
+		}
+	case []interface{}:
+		for _, value := range data {
+			if redact(value) {
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+// TrafficRecord is a line in a traffic recording
+type TrafficRecord struct {
+	Time    time.Time `json:"time"`
+	Session string    `json:"session"`
+	// Direction is one of opened, sent, received and closed
+	Direction string          `json:"direction"`
+	URL       string          `json:"url,omitempty"`
+	Message   json.RawMessage `json:"message,omitempty"`
+}
+
+// trafficRecording is the file all traffic is recorded to, shared by all connections
+type trafficRecording struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+}
+
+var recording *trafficRecording
+
+// RecordTraffic starts recording all websocket traffic as JSON Lines to the file
+func RecordTraffic(path string) {
+	file, err := os.Create(path)
+	if err != nil {
+		Fatalf("could not create traffic recording '%s': %s\n", path, err)
+	}
+	recording = &trafficRecording{encoder: json.NewEncoder(file)}
+}
+
+func (r *trafficRecording) write(record *TrafficRecord) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	record.Time = time.Now().UTC()
+	r.encoder.Encode(record)
+}
+
+// TrafficRecorder is a TrafficLogger that records the traffic of a session and prints it if traffic is logged
+type TrafficRecorder struct {
+	session string
+	url     string
+	// logger is set when traffic is logged as well
+	logger *TrafficLogger
+}
+
+// NewTrafficLogger returns a TrafficLogger for a connection to the engine, or nil if traffic is neither
+// logged nor recorded
+func NewTrafficLogger(session, url string) enigma.TrafficLogger {
+	switch {
+	case recording != nil:
+		recorder := &TrafficRecorder{session: session, url: url}
+		if Traffic {
+			recorder.logger = newTrafficLogger(trafficOptions)
+		}
+		return recorder
+	case Traffic:
+		return newTrafficLogger(trafficOptions)
+	}
+	return nil
+}
 
 // Opened implements Opened() method in enigma-go TrafficLogger interface
-func (TrafficLogger) Opened() {}
+func (r *TrafficRecorder) Opened() {
+	recording.write(&TrafficRecord{Session: r.session, Direction: "opened", URL: r.url})
+}
 
 // Sent implements Sent() method in enigma-go TrafficLogger interface
-func (TrafficLogger) Sent(message []byte) {
-	fmt.Println("-->", string(message))
+func (r *TrafficRecorder) Sent(message []byte) {
+	if r.logger != nil {
+		r.logger.Sent(message)
+	}
+	recording.write(&TrafficRecord{Session: r.session, Direction: "sent", Message: append(json.RawMessage{}, RedactSecrets(message)...)})
 }
 
 // Received implements Received() method in enigma-go TrafficLogger interface
-func (TrafficLogger) Received(message []byte) {
-	fmt.Println("<--", string(message))
+func (r *TrafficRecorder) Received(message []byte) {
+	if r.logger != nil {
+		r.logger.Received(message)
+	}
+	recording.write(&TrafficRecord{Session: r.session, Direction: "received", Message: append(json.RawMessage{}, RedactSecrets(message)...)})
 }
 
 // Closed implements Closed() method in enigma-go TrafficLogger interface
-func (TrafficLogger) Closed() {}
+func (r *TrafficRecorder) Closed() {
+	recording.write(&TrafficRecord{Session: r.session, Direction: "closed"})
+}
@@ -0,0 +1,38 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	message := []byte(`{"id":1,"method":"CreateConnection","handle":1,"params":[{"qName":"db","qUserName":"me","qPassword":"secret"}]}`)
+	redactedMessage := string(RedactSecrets(message))
+	assert.NotContains(t, redactedMessage, "secret")
+	assert.Contains(t, redactedMessage, `"qPassword":"***"`)
+	assert.Contains(t, redactedMessage, `"qUserName":"me"`)
+
+	unchanged := []byte(`{"id":2, "method":"GetLayout", "handle":2, "params":[]}`)
+	assert.Equal(t, unchanged, RedactSecrets(unchanged))
+}
+
+func TestTrafficLoggerFilter(t *testing.T) {
+	out := &bytes.Buffer{}
+	trafficOutput = out
+	defer func() { trafficOutput = os.Stdout }()
+	logger := newTrafficLogger(&TrafficOptions{Methods: []string{"getlayout"}, Truncate: 20, Timing: true})
+
+	logger.Sent([]byte(`{"jsonrpc":"2.0","id":1,"method":"GetLayout","handle":2,"params":[]}`))
+	logger.Sent([]byte(`{"jsonrpc":"2.0","id":2,"method":"GetScript","handle":1,"params":[]}`))
+	logger.Received([]byte(`{"jsonrpc":"2.0","id":2,"result":{"qScript":"Load 1 as a AutoGenerate 1;"}}`))
+	logger.Received([]byte(`{"jsonrpc":"2.0","id":1,"result":{"qLayout":{}}}`))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.Equal(t, `--> {"jsonrpc":"2.0","id... (68 bytes)`, lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "<-- (GetLayout "))
+}
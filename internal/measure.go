@@ -16,6 +16,21 @@ type Measure struct {
 	Info *enigma.NxInfo `json:"qInfo,omitempty"`
 }
 
+// MeasureDef describes a master measure created from the command line
+type MeasureDef struct {
+	ID         string
+	Label      string
+	Expression string
+	Format     string
+	Tags       []string
+}
+
+type measureProperties struct {
+	Info    *enigma.NxInfo              `json:"qInfo"`
+	Measure *enigma.NxLibraryMeasureDef `json:"qMeasure"`
+	MetaDef *MasterItemMetaDef          `json:"qMetaDef"`
+}
+
 func (m Measure) validate() error {
 	if m.Info == nil {
 		return errors.New("missing qInfo attribute")
@@ -46,9 +61,15 @@ func ListMeasures(ctx context.Context, doc *enigma.Doc) []NamedItem {
 			}`),
 		},
 	}
-	sessionObject, _ := doc.CreateSessionObject(ctx, props)
+	sessionObject, err := doc.CreateSessionObject(ctx, props)
+	if err != nil {
+		log.Fatalf("could not list measures: %s\n", err)
+	}
 	defer doc.DestroySessionObject(ctx, sessionObject.GenericId)
-	layout, _ := sessionObject.GetLayout(ctx)
+	layout, err := sessionObject.GetLayout(ctx)
+	if err != nil {
+		log.Fatalf("could not list measures: %s\n", err)
+	}
 
 	unsortedResult := make(map[string]*NamedItem)
 	keys := make([]string, len(unsortedResult))
@@ -80,31 +101,28 @@ func SetMeasures(ctx context.Context, doc *enigma.Doc, commandLineGlobPattern st
 		if err != nil {
 			log.Fatalf("could not parse file %s: %s\n", path, err)
 		}
-		ch := make(chan error)
-
-		for _, raw := range rawEntities {
-			go func(raw json.RawMessage) {
-				var measure Measure
-				err := json.Unmarshal(raw, &measure)
-				if err != nil {
-					ch <- fmt.Errorf("could not parse data in file %s: %s", path, err)
-					return
-				}
-				err = measure.validate()
-				if err != nil {
-					ch <- fmt.Errorf("validation error in file %s: %s", path, err)
-					return
-				}
-				ch <- setMeasure(ctx, doc, measure.Info.Id, raw)
-			}(raw)
-		}
+		errs := forEach(len(rawEntities), "", func(i int) error {
+			raw := rawEntities[i]
+			var measure Measure
+			err := json.Unmarshal(raw, &measure)
+			if err != nil {
+				return newEntityError(path, fmt.Errorf("could not parse data in file %s: %s", path, err))
+			}
+			err = measure.validate()
+			if err != nil {
+				return newEntityError(path, fmt.Errorf("validation error in file %s: %s", path, err))
+			}
+			return newEntityError(measure.Info.Id, retrySet(ctx, "measure", measure.Info.Id, func() error {
+				return setMeasure(ctx, doc, measure.Info.Id, raw)
+			}))
+		})
 
-		// Loop through the responses and see if there are any failures, if so exit with a fatal
+		// See if there are any failures, if so exit with a fatal
 		success := true
-		for range rawEntities {
-			err := <-ch
+		for _, err := range errs {
 			if err != nil {
 				log.Errorln(err)
+				reportEntityError("measures", err)
 				success = false
 			}
 		}
@@ -135,3 +153,59 @@ func setMeasure(ctx context.Context, doc *enigma.Doc, measureID string, raw json
 	}
 	return nil
 }
+
+// CreateMeasure validates the expression of the measure definition and creates or updates the
+// master measure. The generated properties are returned so they can be stored in the project.
+func CreateMeasure(ctx context.Context, doc *enigma.Doc, def MeasureDef) json.RawMessage {
+	if def.Expression == "" {
+		log.Fatalln("no expression specified")
+	}
+	if err := checkExpression(ctx, doc, def.Expression); err != nil {
+		log.Fatalln(err)
+	}
+	title := def.Label
+	if title == "" {
+		title = def.Expression
+	}
+	id := def.ID
+	if id == "" {
+		id = buildEntityID("measure", title)
+	}
+	props := &measureProperties{
+		Info: &enigma.NxInfo{
+			Id:   id,
+			Type: "measure",
+		},
+		Measure: &enigma.NxLibraryMeasureDef{
+			Label: def.Label,
+			Def:   def.Expression,
+		},
+		MetaDef: &MasterItemMetaDef{
+			Title: title,
+			Tags:  def.Tags,
+		},
+	}
+	if def.Format != "" {
+		props.Measure.NumFormat = &enigma.FieldAttributes{
+			Type: "F",
+			Fmt:  def.Format,
+		}
+	}
+	raw := marshalOrFail(props)
+	if err := setMeasure(ctx, doc, id, raw); err != nil {
+		log.Fatalln(err)
+	}
+	log.Info("Measure set with ID: ")
+	log.Quietln(id)
+	return raw
+}
+
+// AppendMeasureToFile adds the measure properties to the measures json file
+func AppendMeasureToFile(path string, raw json.RawMessage) {
+	var measure Measure
+	json.Unmarshal(raw, &measure)
+	if err := appendEntityToFile(path, measure.Info.Id, raw); err != nil {
+		log.Fatalf("could not append measure to %s: %s\n", path, err)
+	}
+	log.Verboseln("Appended measure " + measure.Info.Id + " to " + path)
+}
@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qlik-oss/corectl/test/fakeengine"
+	"github.com/qlik-oss/enigma-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAndListMeasures(t *testing.T) {
+	ctx := context.Background()
+	engine := fakeengine.New()
+	assert.Nil(t, engine.Start())
+	defer engine.Close()
+	engine.AddApp("app")
+	global, err := enigma.Dialer{}.Dial(ctx, engine.URL, nil)
+	assert.Nil(t, err)
+	defer global.DisconnectFromServer()
+	doc, err := global.OpenDoc(ctx, "app", "", "", "", false)
+	assert.Nil(t, err)
+
+	dir, err := ioutil.TempDir("", "corectl")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "measures.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`[
+		{"qInfo":{"qId":"m2","qType":"measure"},"qMeasure":{"qDef":"Sum(b)"},"qMetaDef":{"title":"B"}},
+		{"qInfo":{"qId":"m1","qType":"measure"},"qMeasure":{"qDef":"Sum(a)"},"qMetaDef":{"title":"A"}}
+	]`), 0644))
+
+	SetMeasures(ctx, doc, path)
+	assert.Equal(t, []NamedItem{{Title: "A", ID: "m1"}, {Title: "B", ID: "m2"}}, ListMeasures(ctx, doc))
+
+	// Setting the measures again updates them
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"qInfo":{"qId":"m1","qType":"measure"},"qMeasure":{"qDef":"Sum(c)"},"qMetaDef":{"title":"C"}}`), 0644))
+	SetMeasures(ctx, doc, path)
+	assert.Equal(t, []NamedItem{{Title: "C", ID: "m1"}, {Title: "B", ID: "m2"}}, ListMeasures(ctx, doc))
+}
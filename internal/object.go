@@ -43,29 +43,34 @@ func (o Object) validate() error {
 
 // ListObjects fetches all generic objects and returns them sorted in an array
 func ListObjects(ctx context.Context, doc *enigma.Doc) []NamedItemWithType {
-	allInfos, _ := doc.GetAllInfos(ctx)
+	allInfos, err := doc.GetAllInfos(ctx)
+	if err != nil {
+		log.Fatalln("could not list the objects of the app:", err)
+	}
 	unsortedResult := make(map[string]*NamedItemWithType)
 	keys := []string{}
+	items := make([]*NamedItemWithType, len(allInfos))
 
-	waitChannel := make(chan *NamedItemWithType)
-	defer close(waitChannel)
-
-	for _, item := range allInfos {
-		go func(item *enigma.NxInfo) {
-			object, _ := doc.GetObject(ctx, item.Id)
-			if object != nil && object.Type != "" {
-				rawProps, _ := object.GetPropertiesRaw(ctx)
-				propsWithTitle := &PropsWithTitle{}
-				json.Unmarshal(rawProps, propsWithTitle)
-				waitChannel <- &NamedItemWithType{Title: propsWithTitle.Meta.Title, ID: item.Id, Type: item.Type}
-			} else {
-				waitChannel <- nil
-			}
-		}(item)
-	}
+	errs := forEach(len(allInfos), "", func(i int) error {
+		item := allInfos[i]
+		object, err := doc.GetObject(ctx, item.Id)
+		if err != nil {
+			return fmt.Errorf("could not get object %s: %s", item.Id, err)
+		} else if object.Type == "" {
+			return nil
+		}
+		rawProps, err := object.GetPropertiesRaw(ctx)
+		if err != nil {
+			return fmt.Errorf("could not get properties of object %s: %s", item.Id, err)
+		}
+		propsWithTitle := &PropsWithTitle{}
+		json.Unmarshal(rawProps, propsWithTitle)
+		items[i] = &NamedItemWithType{Title: propsWithTitle.Meta.Title, ID: item.Id, Type: item.Type}
+		return nil
+	})
+	failOnErrors(errs, "could not list all objects of the app")
 	//Put all responses into a map by their Id
-	for range allInfos {
-		item := <-waitChannel
+	for _, item := range items {
 		if item != nil {
 			keys = append(keys, item.ID)
 			unsortedResult[item.ID] = item
@@ -92,32 +97,32 @@ func SetObjects(ctx context.Context, doc *enigma.Doc, commandLineGlobPattern str
 			log.Fatalf("could not parse file %s: %s\n", path, err)
 		}
 
-		// Run in parallel
-		ch := make(chan error)
-
-		for _, raw := range rawEntities {
-			go func(raw json.RawMessage) {
-				var object Object
-				err = json.Unmarshal(raw, &object)
-				if err != nil {
-					ch <- fmt.Errorf("could not parse data in file %s: %s", path, err)
-					return
-				}
-				err = object.validate()
-				if err != nil {
-					ch <- fmt.Errorf("validation error in file %s: %s", path, err)
-					return
-				}
-				ch <- setObject(ctx, doc, object.Info, object.Properties, raw)
-			}(raw)
-		}
+		errs := forEach(len(rawEntities), "", func(i int) error {
+			raw := rawEntities[i]
+			var object Object
+			err := json.Unmarshal(raw, &object)
+			if err != nil {
+				return newEntityError(path, fmt.Errorf("could not parse data in file %s: %s", path, err))
+			}
+			err = object.validate()
+			if err != nil {
+				return newEntityError(path, fmt.Errorf("validation error in file %s: %s", path, err))
+			}
+			info := object.Info
+			if info == nil {
+				info = object.Properties.Info
+			}
+			return newEntityError(info.Id, retrySet(ctx, "object", info.Id, func() error {
+				return setObject(ctx, doc, object.Info, object.Properties, raw)
+			}))
+		})
 
-		// Loop through the responses and see if there are any failures, if so exit with a fatal
+		// See if there are any failures, if so exit with a fatal
 		success := true
-		for range rawEntities {
-			err := <-ch
+		for _, err := range errs {
 			if err != nil {
 				log.Errorln(err)
+				reportEntityError("objects", err)
 				success = false
 			}
 		}
@@ -0,0 +1,196 @@
+package internal
+
+// bundledSchemas contains the JSON schemas used to validate entity files. The schemas named after a qType or
+// visualization are used for entities of that type, the rest are definitions shared between them. The schemas
+// only describe well known properties so that custom properties are still allowed. Charts linked to a master
+// visualization through qExtendsId get their definition from it and do not need one of their own.
+var bundledSchemas = parseSchemas(map[string]string{
+	"generic": `{
+		"type": "object",
+		"required": ["qInfo"],
+		"properties": {
+			"qInfo": {
+				"type": "object",
+				"required": ["qId", "qType"],
+				"properties": {
+					"qId": {"type": "string", "minLength": 1},
+					"qType": {"type": "string", "minLength": 1}
+				}
+			},
+			"qExtendsId": {"type": "string"},
+			"qMetaDef": {
+				"type": "object",
+				"properties": {
+					"title": {"type": "string"},
+					"description": {"type": "string"},
+					"tags": {"type": "array", "items": {"type": "string"}}
+				}
+			},
+			"visualization": {"type": "string"},
+			"qHyperCubeDef": {"$ref": "#/definitions/hyperCubeDef"},
+			"qListObjectDef": {"$ref": "#/definitions/listObjectDef"},
+			"qChildListDef": {
+				"type": "object",
+				"properties": {"qData": {"type": "object"}}
+			}
+		}
+	}`,
+	"hyperCubeDef": `{
+		"type": "object",
+		"properties": {
+			"qDimensions": {"type": "array", "items": {"$ref": "#/definitions/nxDimension"}},
+			"qMeasures": {"type": "array", "items": {"$ref": "#/definitions/nxMeasure"}},
+			"qInterColumnSortOrder": {"type": "array", "items": {"type": "integer", "minimum": 0}},
+			"qSuppressZero": {"type": "boolean"},
+			"qSuppressMissing": {"type": "boolean"},
+			"qInitialDataFetch": {"$ref": "#/definitions/initialDataFetch"}
+		}
+	}`,
+	"listObjectDef": `{
+		"type": "object",
+		"properties": {
+			"qLibraryId": {"type": "string"},
+			"qDef": {"$ref": "#/definitions/nxInlineDimensionDef"},
+			"qInitialDataFetch": {"$ref": "#/definitions/initialDataFetch"}
+		}
+	}`,
+	"nxDimension": `{
+		"type": "object",
+		"properties": {
+			"qLibraryId": {"type": "string"},
+			"qDef": {"$ref": "#/definitions/nxInlineDimensionDef"},
+			"qNullSuppression": {"type": "boolean"}
+		}
+	}`,
+	"nxInlineDimensionDef": `{
+		"type": "object",
+		"properties": {
+			"qGrouping": {"$ref": "#/definitions/grouping"},
+			"qFieldDefs": {"type": "array", "items": {"type": "string"}},
+			"qFieldLabels": {"type": "array", "items": {"type": "string"}}
+		}
+	}`,
+	"nxMeasure": `{
+		"type": "object",
+		"properties": {
+			"qLibraryId": {"type": "string"},
+			"qDef": {
+				"type": "object",
+				"properties": {
+					"qLabel": {"type": "string"},
+					"qDef": {"type": "string"},
+					"qGrouping": {"$ref": "#/definitions/grouping"}
+				}
+			}
+		}
+	}`,
+	"initialDataFetch": `{
+		"type": "array",
+		"items": {
+			"type": "object",
+			"properties": {
+				"qTop": {"type": "integer", "minimum": 0},
+				"qLeft": {"type": "integer", "minimum": 0},
+				"qWidth": {"type": "integer", "minimum": 0},
+				"qHeight": {"type": "integer", "minimum": 0}
+			}
+		}
+	}`,
+	"grouping": `{"type": "string", "enum": ["N", "H", "C"]}`,
+	"measure": `{
+		"allOf": [{"$ref": "#/definitions/generic"}],
+		"required": ["qMeasure"],
+		"properties": {
+			"qInfo": {"properties": {"qType": {"enum": ["measure"]}}},
+			"qMeasure": {
+				"type": "object",
+				"required": ["qDef"],
+				"properties": {
+					"qLabel": {"type": "string"},
+					"qDef": {"type": "string", "minLength": 1},
+					"qGrouping": {"$ref": "#/definitions/grouping"},
+					"qNumFormat": {"type": "object"}
+				}
+			}
+		}
+	}`,
+	"dimension": `{
+		"allOf": [{"$ref": "#/definitions/generic"}],
+		"required": ["qDim"],
+		"properties": {
+			"qInfo": {"properties": {"qType": {"enum": ["dimension"]}}},
+			"qDim": {
+				"type": "object",
+				"required": ["qFieldDefs"],
+				"properties": {
+					"qGrouping": {"$ref": "#/definitions/grouping"},
+					"qFieldDefs": {"type": "array", "minItems": 1, "items": {"type": "string", "minLength": 1}},
+					"qFieldLabels": {"type": "array", "items": {"type": "string"}}
+				}
+			}
+		}
+	}`,
+	"variable": `{
+		"allOf": [{"$ref": "#/definitions/generic"}],
+		"required": ["qName"],
+		"properties": {
+			"qInfo": {"properties": {"qType": {"enum": ["variable"]}}},
+			"qName": {"type": "string", "minLength": 1},
+			"qComment": {"type": "string"},
+			"qDefinition": {"type": "string"},
+			"qIncludeInBookmark": {"type": "boolean"}
+		}
+	}`,
+	"bookmark": `{
+		"allOf": [{"$ref": "#/definitions/generic"}],
+		"properties": {
+			"qInfo": {"properties": {"qType": {"enum": ["bookmark"]}}}
+		}
+	}`,
+	"sheet": `{
+		"allOf": [{"$ref": "#/definitions/generic"}],
+		"properties": {
+			"columns": {"type": ["integer", "string"]},
+			"rows": {"type": ["integer", "string"]},
+			"cells": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"required": ["name", "type"],
+					"properties": {
+						"name": {"type": "string", "minLength": 1},
+						"type": {"type": "string", "minLength": 1},
+						"col": {"type": "integer", "minimum": 0},
+						"row": {"type": "integer", "minimum": 0},
+						"colspan": {"type": "integer", "minimum": 1},
+						"rowspan": {"type": "integer", "minimum": 1}
+					}
+				}
+			}
+		}
+	}`,
+	"chart": `{
+		"allOf": [{"$ref": "#/definitions/generic"}],
+		"if": {"required": ["qExtendsId"]},
+		"else": {"required": ["qHyperCubeDef"]}
+	}`,
+	"table":       `{"$ref": "#/definitions/chart"}`,
+	"pivot-table": `{"$ref": "#/definitions/chart"}`,
+	"barchart":    `{"$ref": "#/definitions/chart"}`,
+	"linechart":   `{"$ref": "#/definitions/chart"}`,
+	"piechart":    `{"$ref": "#/definitions/chart"}`,
+	"combochart":  `{"$ref": "#/definitions/chart"}`,
+	"scatterplot": `{"$ref": "#/definitions/chart"}`,
+	"treemap":     `{"$ref": "#/definitions/chart"}`,
+	"kpi": `{
+		"allOf": [{"$ref": "#/definitions/chart"}],
+		"properties": {
+			"qHyperCubeDef": {"properties": {"qMeasures": {"minItems": 1}}}
+		}
+	}`,
+	"listbox": `{
+		"allOf": [{"$ref": "#/definitions/generic"}],
+		"if": {"required": ["qExtendsId"]},
+		"else": {"required": ["qListObjectDef"]}
+	}`,
+})
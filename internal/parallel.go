@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"sync"
+
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/spf13/viper"
+)
+
+// Progress is only reported for work with at least this many items
+const progressThreshold = 100
+
+// parallelism returns the maximum number of concurrent engine requests, set with the parallelism flag
+func parallelism() int {
+	if n := viper.GetInt("parallelism"); n > 0 {
+		return n
+	}
+	return 1
+}
+
+// forEach calls f with the indexes 0 to n-1 from a pool of at most parallelism goroutines. The errors returned
+// by f are returned by index, so that they can be reported in a deterministic order. If description is set and
+// there are many items the progress is logged, e.g. 'Fetched entities: 300 of 3000'.
+func forEach(n int, description string, f func(i int) error) []error {
+	errs := make([]error, n)
+	workers := parallelism()
+	if workers > n {
+		workers = n
+	}
+	progress := newWorkProgress(description, n)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = f(i)
+				progress.done()
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return errs
+}
+
+// failOnErrors logs the errors in order and exits with the message if there are any
+func failOnErrors(errs []error, message string) {
+	failed := false
+	for _, err := range errs {
+		if err != nil {
+			log.Errorln(err)
+			failed = true
+		}
+	}
+	if failed {
+		log.Fatalln(message)
+	}
+}
+
+// workProgress logs the number of finished items for every tenth of the work
+type workProgress struct {
+	description string
+	total       int
+	finished    int
+	mutex       sync.Mutex
+}
+
+func newWorkProgress(description string, total int) *workProgress {
+	if description == "" || total < progressThreshold {
+		return nil
+	}
+	return &workProgress{description: description, total: total}
+}
+
+func (p *workProgress) done() {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.finished++
+	if p.finished*10/p.total != (p.finished-1)*10/p.total {
+		log.Infof("%s: %d of %d\n", p.description, p.finished, p.total)
+	}
+}
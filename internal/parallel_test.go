@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/qlik-oss/corectl/test/fakeengine"
+	"github.com/qlik-oss/enigma-go"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEachBoundsConcurrency(t *testing.T) {
+	viper.Set("parallelism", 3)
+	defer viper.Set("parallelism", nil)
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	release := make(chan struct{})
+	go func() {
+		for i := 0; i < 20; i++ {
+			release <- struct{}{}
+		}
+	}()
+	errs := forEach(20, "", func(i int) error {
+		mutex.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mutex.Unlock()
+		<-release
+		mutex.Lock()
+		running--
+		mutex.Unlock()
+		if i%5 == 0 {
+			return fmt.Errorf("item %d failed", i)
+		}
+		return nil
+	})
+	assert.True(t, maxRunning <= 3)
+	assert.Equal(t, 20, len(errs))
+	failed := []error{}
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	assert.Equal(t, []error{errors.New("item 0 failed"), errors.New("item 5 failed"), errors.New("item 10 failed"), errors.New("item 15 failed")}, failed)
+}
+
+func TestCollectEntities(t *testing.T) {
+	viper.Set("parallelism", 2)
+	defer viper.Set("parallelism", nil)
+	ctx := context.Background()
+	engine := fakeengine.New()
+	assert.Nil(t, engine.Start())
+	defer engine.Close()
+	app := engine.AddApp("app")
+	for i := 0; i < 5; i++ {
+		id := fmt.Sprintf("m%d", i)
+		app.Entities[id] = &fakeengine.Entity{Kind: "measure", Properties: map[string]interface{}{"qInfo": map[string]interface{}{"qId": id, "qType": "measure"}}}
+	}
+	app.Entities["d1"] = &fakeengine.Entity{Kind: "dimension", Properties: map[string]interface{}{"qInfo": map[string]interface{}{"qId": "d1", "qType": "dimension"}}}
+	app.Entities["sheet1"] = &fakeengine.Entity{Kind: "object", Properties: map[string]interface{}{"qInfo": map[string]interface{}{"qId": "sheet1", "qType": "sheet"}}}
+	global, err := enigma.Dialer{}.Dial(ctx, engine.URL, nil)
+	assert.Nil(t, err)
+	defer global.DisconnectFromServer()
+	doc, err := global.OpenDoc(ctx, "app", "", "", "", false)
+	assert.Nil(t, err)
+
+	measures, dimensions, objects := collectEntities(ctx, doc, "")
+	assert.Equal(t, 5, len(measures))
+	assert.Equal(t, 1, len(dimensions))
+	assert.Equal(t, 1, len(objects))
+	info := struct {
+		Info struct {
+			ID string `json:"qId"`
+		} `json:"qInfo"`
+	}{}
+	json.Unmarshal(objects[0].JSON, &info)
+	assert.Equal(t, "sheet1", info.Info.ID)
+}
@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/qlik-oss/enigma-go"
+)
+
+type (
+	// ProgressEvent is an event in the JSON Lines stream describing the progress of a reload
+	ProgressEvent struct {
+		// Type is one of reload-started, progress, error and reload-finished, or log and output for the messages
+		// and results of the command when the events are written to stdout
+		Type       string    `json:"type"`
+		Time       time.Time `json:"time"`
+		Persistent bool      `json:"persistent,omitempty"`
+		Statement  string    `json:"statement,omitempty"`
+		Table      string    `json:"table,omitempty"`
+		Source     string    `json:"source,omitempty"`
+		Rows       int       `json:"rows,omitempty"`
+		Success    *bool     `json:"success,omitempty"`
+		Error      string    `json:"error,omitempty"`
+		Level      string    `json:"level,omitempty"`
+		Message    string    `json:"message,omitempty"`
+		// Data is the result printed by the command
+		Data json.RawMessage `json:"data,omitempty"`
+	}
+
+	// ProgressEventWriter writes the progress of a reload as JSON Lines
+	ProgressEventWriter struct {
+		encoder       *json.Encoder
+		lastTransient string
+		// file is the file the events are written to, if created by the writer
+		file *os.File
+		// err is the first error writing an event
+		err error
+	}
+)
+
+// NewProgressEventWriter creates a writer of progress events
+func NewProgressEventWriter(w io.Writer) *ProgressEventWriter {
+	return &ProgressEventWriter{encoder: json.NewEncoder(w)}
+}
+
+// NewProgressEventFile creates a writer of progress events to a new file at the path, which is closed by Close
+func NewProgressEventFile(path string) (*ProgressEventWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	writer := NewProgressEventWriter(file)
+	writer.file = file
+	return writer, nil
+}
+
+// Close closes the file of the writer, if any, and returns the first error writing or closing it. Since the
+// reload does not stop on errors writing events, they are only reported here. Closing it again does nothing.
+func (w *ProgressEventWriter) Close() error {
+	err := w.err
+	w.err = nil
+	if w.file != nil {
+		if closeErr := w.file.Close(); err == nil {
+			err = closeErr
+		}
+		w.file = nil
+	}
+	return err
+}
+
+func (w *ProgressEventWriter) write(event *ProgressEvent) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	if err := w.encoder.Encode(event); err != nil && w.err == nil {
+		w.err = err
+	}
+}
+
+// Message writes a log message as an event, see log.JSONLinesWriter
+func (w *ProgressEventWriter) Message(level, message string) {
+	w.write(&ProgressEvent{Type: "log", Level: level, Message: message})
+}
+
+// Data writes the result printed by the command as an event, see log.JSONLinesWriter
+func (w *ProgressEventWriter) Data(data json.RawMessage) {
+	w.write(&ProgressEvent{Type: "output", Data: data})
+}
+
+// started writes the event that the reload has started
+func (w *ProgressEventWriter) started() {
+	w.write(&ProgressEvent{Type: "reload-started"})
+}
+
+// finished writes the event that the reload has finished, along with the error if it failed
+func (w *ProgressEventWriter) finished(success bool, err error) {
+	event := &ProgressEvent{Type: "reload-finished", Success: &success}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	w.write(event)
+}
+
+// progress writes an event for each line of progress and each script error. Transient progress is only
+// written when it has changed since the last request.
+func (w *ProgressEventWriter) progress(progress *enigma.ProgressData) {
+	now := time.Now().UTC()
+	for _, line := range strings.Split(progress.PersistentProgress, "\n") {
+		w.writeProgressLine(line, true, now)
+	}
+	if progress.TransientProgress != w.lastTransient {
+		w.lastTransient = progress.TransientProgress
+		w.writeProgressLine(progress.TransientProgress, false, now)
+	}
+	for _, errorData := range progress.ErrorData {
+		w.write(&ProgressEvent{Type: "error", Time: now, Statement: strings.TrimSpace(errorData.Line), Error: errorData.ErrorString})
+	}
+}
+
+func (w *ProgressEventWriter) writeProgressLine(line string, persistent bool, at time.Time) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	event := &ProgressEvent{Type: "progress", Time: at, Persistent: persistent, Statement: line}
+	if match := tableProgressRegexp.FindStringSubmatch(line); match != nil {
+		event.Table = match[1]
+		event.Source = match[2]
+		event.Rows = parseRowCount(match[3])
+	}
+	w.write(event)
+}
@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/qlik-oss/enigma-go"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressEventWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer := NewProgressEventWriter(&buf)
+	writer.started()
+	writer.progress(&enigma.ProgressData{PersistentProgress: "Connected", TransientProgress: "Sales << sales.csv 500 Lines fetched"})
+	writer.progress(&enigma.ProgressData{TransientProgress: "Sales << sales.csv 500 Lines fetched"})
+	writer.progress(&enigma.ProgressData{
+		PersistentProgress: "Sales << sales.csv 1,200 Lines fetched",
+		ErrorData:          []*enigma.ErrorData{{ErrorString: "Field 'x' not found", Line: "LOAD x FROM y; "}},
+	})
+	writer.finished(false, nil)
+
+	events := []*ProgressEvent{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		event := &ProgressEvent{}
+		assert.NoError(t, json.Unmarshal([]byte(line), event))
+		assert.False(t, event.Time.IsZero())
+		events = append(events, event)
+	}
+	assert.Len(t, events, 6)
+	assert.Equal(t, "reload-started", events[0].Type)
+	assert.Equal(t, &ProgressEvent{Type: "progress", Time: events[1].Time, Persistent: true, Statement: "Connected"}, events[1])
+	assert.Equal(t, "Sales", events[2].Table)
+	assert.Equal(t, 500, events[2].Rows)
+	assert.False(t, events[2].Persistent)
+	assert.Equal(t, 1200, events[3].Rows)
+	assert.True(t, events[3].Persistent)
+	assert.Equal(t, "error", events[4].Type)
+	assert.Equal(t, "LOAD x FROM y;", events[4].Statement)
+	assert.Equal(t, "reload-finished", events[5].Type)
+	assert.False(t, *events[5].Success)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestProgressEventFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "corectl-events")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "events.jsonl")
+
+	writer, err := NewProgressEventFile(path)
+	assert.NoError(t, err)
+	writer.started()
+	writer.finished(true, nil)
+	assert.NoError(t, writer.Close())
+	assert.NoError(t, writer.Close())
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(content), "\n"))
+
+	_, err = NewProgressEventFile(filepath.Join(dir, "missing", "events.jsonl"))
+	assert.Error(t, err)
+
+	writer = NewProgressEventWriter(failingWriter{})
+	writer.started()
+	assert.EqualError(t, writer.Close(), "disk full")
+}
+
+func TestProgressEventWriterLog(t *testing.T) {
+	viper.Set("json", true)
+	log.Init()
+	defer func() {
+		viper.Set("json", nil)
+		log.Init()
+	}()
+	var buf bytes.Buffer
+	log.SetJSONLinesWriter(NewProgressEventWriter(&buf))
+	defer log.SetJSONLinesWriter(nil)
+
+	log.Warnf("Table %s is empty\n", "Sales")
+	log.Infoln("Reload finished successfully")
+	log.PrintAsJSON(map[string]interface{}{"duration": 1.5, "steps": []string{"Sales"}})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+	events := make([]*ProgressEvent, len(lines))
+	for i, line := range lines {
+		events[i] = &ProgressEvent{}
+		assert.NoError(t, json.Unmarshal([]byte(line), events[i]))
+	}
+	assert.Equal(t, &ProgressEvent{Type: "log", Time: events[0].Time, Level: "warning", Message: "Table Sales is empty"}, events[0])
+	assert.Equal(t, "info", events[1].Level)
+	assert.Equal(t, "output", events[2].Type)
+	assert.JSONEq(t, `{"duration": 1.5, "steps": ["Sales"]}`, string(events[2].Data))
+}
@@ -0,0 +1,190 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/spf13/viper"
+)
+
+// ProjectApp is an entry in the apps list of the config file. The properties of the entry override the top level
+// properties of the config file, such as connections, variables and headers, which are shared by all apps.
+type ProjectApp struct {
+	// App is the name or identifier of the app
+	App string
+	// Root is the folder, relative to the config file, that the paths set by the entry are relative to
+	Root string
+	// properties are the properties of the entry, except app and root
+	properties map[interface{}]interface{}
+}
+
+// projectApps are the apps listed in the config file
+var projectApps []*ProjectApp
+
+// selectedProjectApp is the entry of the app given by --app, if it is listed in the config file
+var selectedProjectApp *ProjectApp
+
+// readProjectApps reads the apps list of the config file
+func readProjectApps(value interface{}, configPath string) []*ProjectApp {
+	if value == nil {
+		return nil
+	}
+	entries, ok := value.([]interface{})
+	if !ok {
+		log.Fatalf("'apps' in config file '%s' must be a list\n", configPath)
+	}
+	apps := []*ProjectApp{}
+	names := map[string]bool{}
+	for i, entry := range entries {
+		properties, ok := entry.(map[interface{}]interface{})
+		if !ok {
+			log.Fatalf("entry %d of 'apps' in config file '%s' must be a map of properties\n", i+1, configPath)
+		}
+		app := &ProjectApp{properties: map[interface{}]interface{}{}}
+		for key, value := range properties {
+			switch key {
+			case "app":
+				app.App = fmt.Sprint(value)
+			case "root":
+				app.Root = fmt.Sprint(value)
+			case "apps", "context":
+				log.Fatalf("'%s' can not be set for an app in config file '%s'\n", key, configPath)
+			default:
+				app.properties[key] = value
+			}
+		}
+		if app.App == "" {
+			log.Fatalf("entry %d of 'apps' in config file '%s' has no 'app'\n", i+1, configPath)
+		}
+		if names[app.App] {
+			log.Fatalf("the app '%s' is listed more than once in config file '%s'\n", app.App, configPath)
+		}
+		names[app.App] = true
+		validateProps(app.properties, configPath)
+		if err := subEnvVars(&app.properties); err != nil {
+			log.Fatalf("bad substitution in '%s': %s\n", configPath, err)
+		}
+		apps = append(apps, app)
+	}
+	return apps
+}
+
+// selectProjectApp selects the listed app with the given name, if there is one, and replaces the top level
+// properties in the config with the properties of the app. Since the config is then read as the config file,
+// flags still take precedence over the properties of the app.
+func selectProjectApp(name string, config map[interface{}]interface{}) bool {
+	selectedProjectApp = nil
+	for _, app := range projectApps {
+		if app.App == name {
+			selectedProjectApp = app
+			break
+		}
+	}
+	if selectedProjectApp == nil {
+		return false
+	}
+	for key, value := range selectedProjectApp.properties {
+		config[key] = value
+	}
+	return true
+}
+
+// HasProjectApps tells whether the config file has an apps list
+func HasProjectApps() bool {
+	return len(projectApps) > 0
+}
+
+// SelectProjectApps returns the listed apps with the given names, in the order of the config file, or all apps
+// if no names are given
+func SelectProjectApps(only []string) []*ProjectApp {
+	if len(only) == 0 {
+		return projectApps
+	}
+	selected := map[string]bool{}
+	for _, name := range only {
+		selected[name] = true
+	}
+	apps := []*ProjectApp{}
+	for _, app := range projectApps {
+		if selected[app.App] {
+			apps = append(apps, app)
+			delete(selected, app.App)
+		}
+	}
+	if len(selected) > 0 {
+		unknown := []string{}
+		for name := range selected {
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unknown)
+		log.Fatalf("unknown apps: %s, the config file lists: %s\n", strings.Join(unknown, ", "), strings.Join(projectAppNames(), ", "))
+	}
+	return apps
+}
+
+func projectAppNames() []string {
+	names := make([]string, len(projectApps))
+	for i, app := range projectApps {
+		names[i] = app.App
+	}
+	return names
+}
+
+// configBaseDir returns the folder that a path set by the config property is relative to, which is the root of
+// the selected app if the app sets the property and the folder of the config file otherwise
+func configBaseDir(param string) string {
+	if app := selectedProjectApp; app != nil && app.Root != "" {
+		if _, ok := app.properties[param]; ok {
+			return filepath.Join(ConfigDir, app.Root)
+		}
+	}
+	return ConfigDir
+}
+
+// ConfigPath returns the path set by the config property, relative to the config file or, if the property is
+// set by the selected app in the apps list, to the root of the app
+func ConfigPath(param string) string {
+	path := viper.GetString(param)
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(configBaseDir(param), path)
+}
+
+// BuildProjectApps runs 'corectl build' once per app, with the app set by --app and the flags appended. The apps
+// are built one at a time, with the output streamed unless quiet is set, or in parallel, bounded by
+// --parallelism, with the output of each build printed when it finishes. A failing build does not stop the others.
+func BuildProjectApps(ctx context.Context, apps []*ProjectApp, flags []string, parallel, quiet bool) []*AppExecResult {
+	executable := corectlExecutable()
+	results := make([]*AppExecResult, len(apps))
+	build := func(i int) error {
+		app := apps[i]
+		args := append([]string{"build", "--app=" + app.App}, flags...)
+		var stream io.Writer
+		if !parallel && !quiet {
+			log.Infof("==> %s <==\n", app.App)
+			stream = os.Stdout
+		}
+		results[i] = runCorectl(ctx, executable, args, stream)
+		if parallel && !quiet {
+			log.Infof("==> %s <==\n%s\n", app.App, results[i].Output)
+		}
+		results[i].AppID = app.App
+		results[i].AppName = app.App
+		return nil
+	}
+	if parallel {
+		forEach(len(apps), "Built apps", build)
+	} else {
+		for i := range apps {
+			build(i)
+		}
+	}
+	return results
+}
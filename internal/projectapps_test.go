@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+var projectConfig = []byte(`engine: localhost:9076
+connections: ./connections.yml
+script: ./script.qvs
+apps:
+  - app: sales.qvf
+    root: ./sales
+    engine: sales-engine:9076
+    script: ./sales.qvs
+    connections:
+      salesData:
+        type: folder
+        connectionstring: /data/sales
+  - app: finance.qvf
+    objects:
+      - ./finance/*.json
+`)
+
+// readTestProjectConfig reads the project config as the config file with the given app selected
+func readTestProjectConfig(t *testing.T, app string) {
+	dir, err := ioutil.TempDir("", "corectl-project")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "corectl.yml")
+	assert.NoError(t, ioutil.WriteFile(configPath, projectConfig, 0644))
+	for _, prop := range []string{"engine", "connections", "script", "objects", "apps"} {
+		AddValidProp(prop)
+	}
+	viper.Set("app", app)
+	defer viper.Set("app", nil)
+	readConfig(configPath, false)
+}
+
+// resetTestProjectConfig restores the config dir and removes the project config from viper
+func resetTestProjectConfig(configDir string) {
+	ConfigDir = configDir
+	projectApps, selectedProjectApp = nil, nil
+	setViperConfig(map[interface{}]interface{}{})
+}
+
+func TestSelectProjectApps(t *testing.T) {
+	defer resetTestProjectConfig(ConfigDir)
+	readTestProjectConfig(t, "")
+	assert.True(t, HasProjectApps())
+	assert.Equal(t, []string{"sales.qvf", "finance.qvf"}, projectAppNames())
+
+	apps := SelectProjectApps([]string{"finance.qvf", "sales.qvf"})
+	assert.Equal(t, "sales.qvf", apps[0].App)
+	assert.Equal(t, "finance.qvf", apps[1].App)
+	assert.Len(t, SelectProjectApps(nil), 2)
+	assert.Len(t, SelectProjectApps([]string{"finance.qvf"}), 1)
+}
+
+func TestProjectAppProperties(t *testing.T) {
+	defer resetTestProjectConfig(ConfigDir)
+	ConfigDir = "/project"
+
+	// Paths set by the app are relative to its root, shared paths to the config file
+	readTestProjectConfig(t, "sales.qvf")
+	assert.Equal(t, "sales-engine:9076", viper.GetString("engine"))
+	assert.Equal(t, filepath.Join("/project", "sales", "sales.qvs"), ConfigPath("script"))
+	connections := *GetConnectionsConfig().Connections
+	assert.Equal(t, "/data/sales", connections["salesData"].ConnectionString)
+
+	// Apps without a root resolve all paths relative to the config file
+	readTestProjectConfig(t, "finance.qvf")
+	assert.Equal(t, "localhost:9076", viper.GetString("engine"))
+	assert.Equal(t, filepath.Join("/project", "script.qvs"), ConfigPath("script"))
+	assert.Equal(t, "/project", configBaseDir("objects"))
+	assert.Equal(t, []string{"./finance/*.json"}, viper.GetStringSlice("objects"))
+
+	// Apps that are not listed use the top level properties
+	readTestProjectConfig(t, "other.qvf")
+	assert.Nil(t, selectedProjectApp)
+	assert.Equal(t, filepath.Join("/project", "connections.yml"), ConfigPath("connections"))
+}
+
+func TestProjectAppPropertiesOverriddenByFlags(t *testing.T) {
+	defer resetTestProjectConfig(ConfigDir)
+	flags := pflag.NewFlagSet("build", pflag.ContinueOnError)
+	flags.String("engine", "", "")
+	assert.NoError(t, viper.BindPFlag("engine", flags.Lookup("engine")))
+	// Viper can not unbind a flag, so the key is bound again to a flag that is never set
+	unset := pflag.NewFlagSet("unset", pflag.ContinueOnError)
+	unset.String("engine", "", "")
+	defer viper.BindPFlag("engine", unset.Lookup("engine"))
+	assert.NoError(t, flags.Set("engine", "flag-engine:9076"))
+
+	readTestProjectConfig(t, "sales.qvf")
+	assert.NotNil(t, selectedProjectApp)
+	assert.Equal(t, "flag-engine:9076", viper.GetString("engine"))
+}
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"time"
 
@@ -14,8 +15,32 @@ import (
 
 var transientLogged bool
 
+// ReloadOptions controls how the app is reloaded
+type ReloadOptions struct {
+	// Silent disables the logging of the reload progress
+	Silent bool
+	// Limit is the maximum number of rows loaded per table, 0 means no limit
+	Limit int
+	// Partial runs a partial reload where only statements prefixed with ADD, REPLACE or MERGE are run
+	Partial bool
+	// Debug runs the reload in debug mode, stopping at the Breakpoints (line numbers in the script, starting at 1)
+	Debug       bool
+	Breakpoints []int
+	// Step runs the reload in debug mode and asks the user how to continue before each statement
+	Step bool
+	// Profile records the progress of the reload, even when silent
+	Profile *ReloadProfile
+	// Events receives the progress of the reload as JSON Lines, even when silent
+	Events *ProgressEventWriter
+}
+
+// debug reports whether the reload must run in debug mode, which is also required to limit the number of rows
+func (o *ReloadOptions) debug() bool {
+	return o.Debug || o.Step || o.Limit > 0
+}
+
 // Reload reloads the app and prints the progress to system out.
-func Reload(ctx context.Context, doc *enigma.Doc, global *enigma.Global, silent bool, limit int) {
+func Reload(ctx context.Context, doc *enigma.Doc, global *enigma.Global, options *ReloadOptions) {
 
 	var (
 		reloadSuccessful  bool
@@ -23,46 +48,91 @@ func Reload(ctx context.Context, doc *enigma.Doc, global *enigma.Global, silent
 		skipTransientLogs bool
 	)
 
-	// Log progress unless silent flag was passed in to the reload command
-	if !silent {
+	ctxWithReservedRequestID, reservedRequestID := doc.WithReservedRequestID(ctx)
+	reloadFinished := make(chan struct{})
+	cancelDone := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancelReload(global, reservedRequestID)
+		case <-reloadFinished:
+		}
+		close(cancelDone)
+	}()
+
+	if options.Limit > 0 {
+		doc.SetFetchLimit(ctx, options.Limit)
+	}
+	debugger := newReloadDebugger(options)
+	if debugger != nil {
+		setBreakpoints(ctx, doc, options.Breakpoints)
+	}
+	if options.Partial {
+		log.Verboseln("Running partial reload")
+	}
+	if options.Events != nil {
+		options.Events.started()
+	}
+
+	// Follow the progress unless silent flag was passed in to the reload command. In debug mode the progress
+	// is needed to continue when the engine pauses.
+	if !options.Silent || options.Profile != nil || options.Events != nil || options.debug() {
 
 		// If not running in a terminal we should skip transient progress logging
 		skipTransientLogs = !terminal.IsTerminal(int(os.Stdout.Fd()))
 
 		reloadDone := make(chan struct{})
 		loggingDone := make(chan struct{})
-		ctxWithReservedRequestID, reservedRequestID := doc.WithReservedRequestID(ctx)
+		interval := time.Second
+		if options.Profile != nil || debugger != nil {
+			interval = profileInterval
+		}
 		go func() {
 			for {
 				select {
 				case <-reloadDone:
-					logProgress(ctx, global, reservedRequestID, skipTransientLogs)
+					logProgress(ctx, doc, global, reservedRequestID, skipTransientLogs, options, debugger)
 					close(loggingDone)
 					return
-				case <-time.After(time.Second):
+				case <-time.After(interval):
 					// Get the progress using the request id we reserved for the reload
-					logProgress(ctx, global, reservedRequestID, skipTransientLogs)
+					logProgress(ctx, doc, global, reservedRequestID, skipTransientLogs, options, debugger)
 				}
 			}
 		}()
-		if limit == 0 {
-			reloadSuccessful, err = doc.DoReload(ctxWithReservedRequestID, 0, false, false)
-		} else {
-			doc.SetFetchLimit(ctx, limit)
-			reloadSuccessful, err = doc.DoReload(ctxWithReservedRequestID, 0, false, true)
-		}
+		reloadSuccessful, err = doc.DoReload(ctxWithReservedRequestID, 0, options.Partial, options.debug())
 
 		close(reloadDone)
 		<-loggingDone
+		if options.Profile != nil {
+			options.Profile.finish(time.Now())
+		}
 	} else {
-		reloadSuccessful, err = doc.DoReload(ctx, 0, false, false)
+		reloadSuccessful, err = doc.DoReload(ctxWithReservedRequestID, 0, options.Partial, false)
 		//fetch the progress but do nothing, othwerwise we will get it for the next non silent call
-		_, getProgressErr := global.GetProgress(ctx, 0)
-		if getProgressErr != nil {
-			log.Errorln(getProgressErr)
+		if ctx.Err() == nil {
+			_, getProgressErr := global.GetProgress(ctx, 0)
+			if getProgressErr != nil {
+				log.Errorln(getProgressErr)
+			}
+		}
+	}
+	close(reloadFinished)
+	<-cancelDone
+	if options.Events != nil {
+		reloadErr := err
+		if ctx.Err() != nil {
+			reloadErr = ctx.Err()
 		}
+		options.Events.finished(reloadSuccessful && reloadErr == nil, reloadErr)
 	}
 
+	if ctx.Err() != nil {
+		log.Fatalln("reload cancelled:", ctx.Err())
+	}
+	if debugger != nil && debugger.aborted {
+		log.Fatalln("reload aborted")
+	}
 	if err != nil {
 		log.Fatalln("could not reload app: ", err)
 	}
@@ -73,38 +143,73 @@ func Reload(ctx context.Context, doc *enigma.Doc, global *enigma.Global, silent
 	log.Infoln("Reload finished successfully")
 }
 
-func logProgress(ctx context.Context, global *enigma.Global, reservedRequestID int, skipTransientLogs bool) {
+func logProgress(ctx context.Context, doc *enigma.Doc, global *enigma.Global, reservedRequestID int, skipTransientLogs bool, options *ReloadOptions, debugger *reloadDebugger) {
 	InteractDef := &enigma.InteractDef{}
+	if ctx.Err() != nil {
+		// The reload has been cancelled
+		return
+	}
 
 	progress, err := global.GetProgress(ctx, reservedRequestID)
 	if err != nil {
 		log.Errorln(err)
 	} else {
+		if options.Profile != nil {
+			now := time.Now()
+			options.Profile.addProgress(progress.PersistentProgress, now)
+			options.Profile.addProgress(progress.TransientProgress, now)
+		}
+		if options.Events != nil {
+			options.Events.progress(progress)
+		}
+		if !options.Silent {
+			printProgress(progress, skipTransientLogs)
+		}
 		// While doing reload in debug mode (required for limit) engine will "pause" and InteractDone has to be sent to continue
 		if progress.UserInteractionWanted {
+			if debugger != nil && !debugger.pause(ctx, doc, global, reservedRequestID) {
+				cancelReload(global, reservedRequestID)
+				return
+			}
 			global.InteractDone(ctx, reservedRequestID, InteractDef)
 		}
+	}
+}
 
-		var text string
-		if progress.TransientProgress != "" {
-			if !skipTransientLogs {
-				text = progress.TransientProgress
-				log.Info("\r" + text)
-				transientLogged = true
-			}
-		} else if progress.PersistentProgress != "" {
-			text = progress.PersistentProgress
-			// If a transient progress was logged we should update that progress with the persistent one
-			if transientLogged {
-				log.Info("\r" + text)
-				transientLogged = false
-			} else {
-				log.Info(text)
-			}
+func printProgress(progress *enigma.ProgressData, skipTransientLogs bool) {
+	var text string
+	if progress.TransientProgress != "" {
+		if !skipTransientLogs {
+			text = progress.TransientProgress
+			log.Info("\r" + text)
+			transientLogged = true
+		}
+	} else if progress.PersistentProgress != "" {
+		text = progress.PersistentProgress
+		// If a transient progress was logged we should update that progress with the persistent one
+		if transientLogged {
+			log.Info("\r" + text)
+			transientLogged = false
+		} else {
+			log.Info(text)
 		}
 	}
 }
 
+// cancelReload stops a reload that is running in the engine. A new context is used since the one
+// of the reload is done.
+func cancelReload(global *enigma.Global, reservedRequestID int) {
+	log.Warnln("Cancelling reload...")
+	ctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+	defer cancel()
+	if err := global.CancelReload(ctx); err != nil {
+		log.Errorln("could not cancel reload:", err)
+	}
+	if err := global.CancelRequest(ctx, reservedRequestID); err != nil {
+		log.Verboseln("could not cancel reload request:", err)
+	}
+}
+
 // Save calls DoSave on the app and prints "Done" if it succeeded or "Save failed" to system out.
 func Save(ctx context.Context, doc *enigma.Doc) {
 	noData := viper.GetBool("no-data")
@@ -122,5 +227,6 @@ func Save(ctx context.Context, doc *enigma.Doc) {
 		log.Infoln("App successfully saved")
 	} else {
 		log.Errorln("Save failed")
+		buildReport.Fail(fmt.Sprintf("save failed: %s", err))
 	}
 }
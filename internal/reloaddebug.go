@@ -0,0 +1,164 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/qlik-oss/enigma-go"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// reloadDebugger decides what to do when a reload in debug mode pauses before a statement. The engine pauses
+// before each statement, the debugger stops at breakpoints and, when stepping, asks the user how to continue.
+type reloadDebugger struct {
+	breakpoints map[int]bool
+	step        bool
+	// runTo is the line to run to without stopping, 0 means stop at the next statement
+	runTo int
+	// continuing is set when running to the next breakpoint
+	continuing bool
+	aborted    bool
+	input      chan string
+}
+
+func newReloadDebugger(options *ReloadOptions) *reloadDebugger {
+	if !options.Debug && !options.Step {
+		return nil
+	}
+	if options.Step && !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		log.Fatalln("stepping through the reload requires an interactive terminal")
+	}
+	// The prompt is logged, which prints nothing when quiet and JSON objects in json mode
+	if options.Step && (viper.GetBool("quiet") || viper.GetBool("json")) {
+		log.Fatalln("--step can not be used with --quiet or --json")
+	}
+	debugger := &reloadDebugger{breakpoints: map[int]bool{}, step: options.Step}
+	for _, line := range options.Breakpoints {
+		if line < 1 {
+			log.Fatalf("invalid breakpoint %d, the lines of the script are numbered from 1\n", line)
+		}
+		debugger.breakpoints[line] = true
+	}
+	return debugger
+}
+
+// scriptBufferName is the name of the engine buffer holding the script of the app
+const scriptBufferName = "Main"
+
+// setBreakpoints replaces the breakpoints of the script with the given line numbers. The lines are numbered from 1,
+// like the lines reported when the reload pauses, while the engine indexes the lines of a buffer from 0.
+func setBreakpoints(ctx context.Context, doc *enigma.Doc, lines []int) {
+	breakpoints := []*enigma.EditorBreakpoint{}
+	for _, line := range lines {
+		breakpoints = append(breakpoints, &enigma.EditorBreakpoint{BufferName: scriptBufferName, LineIx: line - 1, Enabled: true})
+	}
+	if err := doc.SetScriptBreakpoints(ctx, breakpoints); err != nil {
+		log.Fatalln("could not set script breakpoints:", err)
+	}
+}
+
+// pause is called when the reload is paused. It returns false if the reload should be aborted.
+func (d *reloadDebugger) pause(ctx context.Context, doc *enigma.Doc, global *enigma.Global, reservedRequestID int) bool {
+	if d.aborted {
+		return false
+	}
+	def, err := global.GetInteract(ctx, reservedRequestID)
+	if err != nil {
+		log.Errorln("could not get the reload position:", err)
+		return true
+	}
+	line := def.NewLineNr
+	atBreakpoint := d.breakpoints[line]
+	switch {
+	case !d.step && !atBreakpoint:
+		return true
+	case d.runTo > 0 && line != d.runTo && !atBreakpoint:
+		return true
+	case d.continuing && !atBreakpoint:
+		return true
+	}
+	d.runTo = 0
+	d.continuing = false
+
+	log.Infof("\nStopped at line %d: %s\n", line, strings.TrimSpace(def.Line))
+	for _, table := range debugTableStates(ctx, doc) {
+		log.Infoln("  " + table)
+	}
+	if !d.step {
+		return true
+	}
+	for {
+		log.Info("[Enter] step, [c] continue to next breakpoint, [b <line>] run to line, [a] abort: ")
+		answer, ok := d.readLine(ctx)
+		if !ok {
+			return true
+		}
+		fields := strings.Fields(answer)
+		switch {
+		case len(fields) == 0 || fields[0] == "s":
+			return true
+		case fields[0] == "c":
+			d.continuing = true
+			return true
+		case fields[0] == "b" && len(fields) == 2:
+			if d.runTo, err = strconv.Atoi(fields[1]); err == nil && d.runTo > 0 {
+				return true
+			}
+			d.runTo = 0
+			log.Errorf("invalid line number '%s'\n", fields[1])
+		case fields[0] == "a":
+			d.aborted = true
+			return false
+		default:
+			log.Errorf("unknown command '%s'\n", answer)
+		}
+	}
+}
+
+// readLine reads a line from stdin. The reading is done in the background so that an interrupted
+// reload does not wait for input.
+func (d *reloadDebugger) readLine(ctx context.Context) (string, bool) {
+	if d.input == nil {
+		d.input = make(chan string)
+		go func() {
+			scanner := bufio.NewScanner(os.Stdin)
+			for scanner.Scan() {
+				d.input <- scanner.Text()
+			}
+			close(d.input)
+		}()
+	}
+	select {
+	case line, ok := <-d.input:
+		return strings.TrimSpace(line), ok
+	case <-ctx.Done():
+		return "", false
+	}
+}
+
+// debugTableStates describes the number of rows and fields of each table loaded so far
+func debugTableStates(ctx context.Context, doc *enigma.Doc) []string {
+	tables, _, err := doc.GetTablesAndKeys(ctx, &enigma.Size{}, &enigma.Size{}, 0, false, false, false)
+	if err != nil {
+		log.Errorln("could not retrieve tables:", err)
+		return nil
+	}
+	if len(tables) == 0 {
+		return []string{"No tables loaded"}
+	}
+	states := []string{}
+	for _, table := range tables {
+		fields := []string{}
+		for _, field := range table.Fields {
+			fields = append(fields, field.Name)
+		}
+		states = append(states, fmt.Sprintf("%s: %d rows (%s)", table.Name, table.NoOfRows, strings.Join(fields, ", ")))
+	}
+	return states
+}
@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/qlik-oss/corectl/test/fakeengine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetBreakpoints(t *testing.T) {
+	engine := fakeengine.New()
+	assert.Nil(t, engine.Start())
+	defer engine.Close()
+	engine.AddApp("app")
+	var breakpoints json.RawMessage
+	engine.Handle("SetScriptBreakpoints", func(session *fakeengine.Session, handle int, params []json.RawMessage) (interface{}, error) {
+		breakpoints = params[0]
+		return map[string]interface{}{}, nil
+	})
+
+	global, doc := openFakeApp(t, engine, "app")
+	defer global.DisconnectFromServer()
+	setBreakpoints(context.Background(), doc, []int{1, 12})
+	// The first line has index 0, which is left out as the default
+	assert.JSONEq(t, `[
+		{"qbufferName": "Main", "qEnabled": true},
+		{"qbufferName": "Main", "qlineIx": 11, "qEnabled": true}
+	]`, string(breakpoints))
+}
+
+func TestPauseAfterAbort(t *testing.T) {
+	// The engine is not asked for the position once aborted, so no connection is needed
+	debugger := &reloadDebugger{breakpoints: map[int]bool{}, step: true, aborted: true}
+	assert.False(t, debugger.pause(context.Background(), nil, nil, 0))
+}
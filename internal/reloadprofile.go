@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qlik-oss/corectl/internal/log"
+)
+
+type (
+	// ReloadProfile is the timing of the steps reported in the progress of a reload. The durations are written to
+	// JSON in seconds.
+	ReloadProfile struct {
+		Start    time.Time     `json:"start"`
+		Duration time.Duration `json:"duration"`
+		Steps    []*ReloadStep `json:"steps"`
+	}
+
+	// ReloadStep is a table being loaded or another statement reported in the progress of a reload. The start,
+	// relative to the start of the reload, and the duration are written to JSON in seconds.
+	ReloadStep struct {
+		Name     string        `json:"name"`
+		Table    string        `json:"table,omitempty"`
+		Source   string        `json:"source,omitempty"`
+		Rows     int           `json:"rows,omitempty"`
+		Start    time.Duration `json:"start"`
+		Duration time.Duration `json:"duration"`
+	}
+
+	traceEvent struct {
+		Name     string                 `json:"name"`
+		Category string                 `json:"cat"`
+		Phase    string                 `json:"ph"`
+		Time     int64                  `json:"ts"`
+		Duration int64                  `json:"dur"`
+		PID      int                    `json:"pid"`
+		TID      int                    `json:"tid"`
+		Args     map[string]interface{} `json:"args,omitempty"`
+	}
+)
+
+// Interval between the progress requests while profiling a reload
+const profileInterval = 200 * time.Millisecond
+
+// Matches progress lines like 'Sales << sales.csv 1,234 Lines fetched'
+var tableProgressRegexp = regexp.MustCompile(`^(.*?)\s*<<\s*(.*?)\s*(?:(\d[\d,.\s]*)\s+[Ll]ines fetched)?$`)
+
+// MarshalJSON writes the duration in seconds
+func (p ReloadProfile) MarshalJSON() ([]byte, error) {
+	type profile ReloadProfile
+	return json.Marshal(&struct {
+		profile
+		Duration float64 `json:"duration"`
+	}{profile(p), p.Duration.Seconds()})
+}
+
+// MarshalJSON writes the start and duration in seconds
+func (s ReloadStep) MarshalJSON() ([]byte, error) {
+	type step ReloadStep
+	return json.Marshal(&struct {
+		step
+		Start    float64 `json:"start"`
+		Duration float64 `json:"duration"`
+	}{step(s), s.Start.Seconds(), s.Duration.Seconds()})
+}
+
+// NewReloadProfile starts profiling a reload
+func NewReloadProfile() *ReloadProfile {
+	return &ReloadProfile{Start: time.Now(), Steps: []*ReloadStep{}}
+}
+
+// addProgress attributes the progress text received at the given time to a step. A step lasts until the next
+// step is reported or the reload finishes, the precision is thus limited by how often progress is requested.
+func (p *ReloadProfile) addProgress(text string, at time.Time) {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		step := &ReloadStep{Name: line}
+		if match := tableProgressRegexp.FindStringSubmatch(line); match != nil {
+			step.Table = match[1]
+			step.Source = match[2]
+			step.Name = match[1]
+			if step.Source != "" {
+				step.Name += " << " + step.Source
+			}
+			step.Rows = parseRowCount(match[3])
+		}
+		current := p.current()
+		if current != nil && current.Name == step.Name {
+			// Updated progress of the current step
+			if step.Rows > current.Rows {
+				current.Rows = step.Rows
+			}
+			continue
+		}
+		step.Start = at.Sub(p.Start)
+		p.endStep(at)
+		p.Steps = append(p.Steps, step)
+	}
+}
+
+// finish ends the last step and the profile
+func (p *ReloadProfile) finish(at time.Time) {
+	p.endStep(at)
+	p.Duration = at.Sub(p.Start)
+}
+
+func (p *ReloadProfile) current() *ReloadStep {
+	if len(p.Steps) == 0 {
+		return nil
+	}
+	return p.Steps[len(p.Steps)-1]
+}
+
+func (p *ReloadProfile) endStep(at time.Time) {
+	if current := p.current(); current != nil {
+		current.Duration = at.Sub(p.Start) - current.Start
+	}
+}
+
+// Slowest returns the steps sorted by duration, the slowest first
+func (p *ReloadProfile) Slowest() []*ReloadStep {
+	steps := append([]*ReloadStep{}, p.Steps...)
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].Duration > steps[j].Duration
+	})
+	return steps
+}
+
+// WriteReloadProfile saves the profile as json
+func WriteReloadProfile(path string, profile *ReloadProfile) {
+	if err := ioutil.WriteFile(path, marshalOrFail(profile), 0644); err != nil {
+		log.Fatalf("could not write profile file '%s': %s\n", path, err)
+	}
+	log.Infoln("Saved reload profile to " + path)
+}
+
+// WriteReloadTrace saves the profile in the Chrome trace event format, which can be opened in
+// chrome://tracing or Perfetto
+func WriteReloadTrace(path string, profile *ReloadProfile) {
+	events := []*traceEvent{{
+		Name:     "reload",
+		Category: "reload",
+		Phase:    "X",
+		Duration: profile.Duration.Microseconds(),
+		PID:      1,
+		TID:      1,
+	}}
+	for _, step := range profile.Steps {
+		event := &traceEvent{
+			Name:     step.Name,
+			Category: "statement",
+			Phase:    "X",
+			Time:     step.Start.Microseconds(),
+			Duration: step.Duration.Microseconds(),
+			PID:      1,
+			TID:      1,
+		}
+		if step.Table != "" {
+			event.Category = "table"
+			event.Args = map[string]interface{}{"table": step.Table, "source": step.Source, "rows": step.Rows}
+		}
+		events = append(events, event)
+	}
+	trace := map[string]interface{}{"traceEvents": events, "displayTimeUnit": "ms"}
+	if err := ioutil.WriteFile(path, marshalOrFail(trace), 0644); err != nil {
+		log.Fatalf("could not write trace file '%s': %s\n", path, err)
+	}
+	log.Infoln("Saved reload trace to " + path)
+}
+
+// parseRowCount parses numbers like '1,234' or '1 234', where the separators depend on the locale
+func parseRowCount(text string) int {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, text)
+	rows, _ := strconv.Atoi(digits)
+	return rows
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadProfile(t *testing.T) {
+	profile := NewReloadProfile()
+	at := func(ms int) time.Time {
+		return profile.Start.Add(time.Duration(ms) * time.Millisecond)
+	}
+	profile.addProgress("Connected", at(100))
+	profile.addProgress("Sales << sales.csv 500 Lines fetched", at(200))
+	profile.addProgress("Sales << sales.csv 1,500 Lines fetched", at(1200))
+	profile.addProgress("Sales << sales.csv 2 000 Lines fetched\nCustomers << customers 40 Lines fetched", at(1400))
+	profile.addProgress("Creating search index", at(1600))
+	profile.finish(at(1700))
+
+	assert.Equal(t, 1700*time.Millisecond, profile.Duration)
+	assert.Len(t, profile.Steps, 4)
+	assert.Equal(t, &ReloadStep{Name: "Connected", Start: 100 * time.Millisecond, Duration: 100 * time.Millisecond}, profile.Steps[0])
+	assert.Equal(t, &ReloadStep{Name: "Sales << sales.csv", Table: "Sales", Source: "sales.csv", Rows: 2000, Start: 200 * time.Millisecond, Duration: 1200 * time.Millisecond}, profile.Steps[1])
+	assert.Equal(t, &ReloadStep{Name: "Customers << customers", Table: "Customers", Source: "customers", Rows: 40, Start: 1400 * time.Millisecond, Duration: 200 * time.Millisecond}, profile.Steps[2])
+	assert.Equal(t, "Creating search index", profile.Steps[3].Name)
+
+	slowest := profile.Slowest()
+	assert.Equal(t, "Sales << sales.csv", slowest[0].Name)
+}
+
+func TestReloadProfileWithoutSource(t *testing.T) {
+	profile := NewReloadProfile()
+	profile.addProgress("TableA <<  5 Lines fetched", profile.Start)
+	assert.Equal(t, "TableA", profile.Steps[0].Name)
+	assert.Equal(t, "TableA", profile.Steps[0].Table)
+	assert.Equal(t, 5, profile.Steps[0].Rows)
+}
+
+func TestReloadProfileJSON(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	profile := &ReloadProfile{Start: start, Duration: 1500 * time.Millisecond, Steps: []*ReloadStep{
+		{Name: "Sales << sales.csv", Table: "Sales", Source: "sales.csv", Rows: 20, Start: 250 * time.Millisecond, Duration: time.Second},
+	}}
+	encoded, err := json.Marshal(profile)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"start": "2024-03-01T12:00:00Z", "duration": 1.5, "steps": [
+		{"name": "Sales << sales.csv", "table": "Sales", "source": "sales.csv", "rows": 20, "start": 0.25, "duration": 1}
+	]}`, string(encoded))
+}
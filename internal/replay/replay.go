@@ -0,0 +1,246 @@
+// Package replay serves recorded websocket traffic back to clients, acting as a Qlik Associative Engine. It makes
+// it possible to run commands and tests against a recording made with --record instead of a live engine.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/qlik-oss/corectl/internal/log"
+)
+
+type (
+	// Server replays the recorded sessions, one per websocket connection
+	Server struct {
+		// URL is the websocket URL of the server once it is listening
+		URL string
+
+		sessions []*session
+		mutex    sync.Mutex
+		listener net.Listener
+		server   *http.Server
+	}
+
+	// session is the recorded traffic of one connection
+	session struct {
+		id string
+		// greeting are the notifications sent by the engine before the first request
+		greeting  []json.RawMessage
+		exchanges []*exchange
+		used      bool
+	}
+
+	// exchange is a request along with the response and the notifications that followed it
+	exchange struct {
+		method        string
+		handle        int
+		params        interface{}
+		id            json.RawMessage
+		response      json.RawMessage
+		notifications []json.RawMessage
+		used          bool
+	}
+
+	rpcMessage struct {
+		ID     json.RawMessage `json:"id,omitempty"`
+		Method string          `json:"method,omitempty"`
+		Handle int             `json:"handle"`
+		Params interface{}     `json:"params,omitempty"`
+	}
+)
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// ReadRecording reads a traffic recording written with --record
+func ReadRecording(path string) ([]*log.TrafficRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	records := []*log.TrafficRecord{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		record := &log.TrafficRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return nil, fmt.Errorf("invalid record on line %d: %s", line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// NewServer creates a server replaying the recorded traffic
+func NewServer(records []*log.TrafficRecord) *Server {
+	server := &Server{}
+	sessions := map[string]*session{}
+	for _, record := range records {
+		current := sessions[record.Session]
+		if current == nil || record.Direction == "opened" {
+			current = &session{id: record.Session}
+			sessions[record.Session] = current
+			server.sessions = append(server.sessions, current)
+		}
+		current.add(record)
+	}
+	return server
+}
+
+func (s *session) add(record *log.TrafficRecord) {
+	if len(record.Message) == 0 {
+		return
+	}
+	message := &rpcMessage{}
+	if err := json.Unmarshal(record.Message, message); err != nil {
+		return
+	}
+	switch record.Direction {
+	case "sent":
+		s.exchanges = append(s.exchanges, &exchange{method: message.Method, handle: message.Handle, params: message.Params, id: message.ID})
+	case "received":
+		if len(message.ID) > 0 {
+			for _, exchange := range s.exchanges {
+				if exchange.response == nil && string(exchange.id) == string(message.ID) {
+					exchange.response = record.Message
+					break
+				}
+			}
+		} else if len(s.exchanges) == 0 {
+			s.greeting = append(s.greeting, record.Message)
+		} else {
+			last := s.exchanges[len(s.exchanges)-1]
+			last.notifications = append(last.notifications, record.Message)
+		}
+	}
+}
+
+// Listen starts serving on the address, e.g. localhost:9076 or localhost:0 for any free port
+func (s *Server) Listen(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	s.URL = "ws://" + listener.Addr().String()
+	s.server = &http.Server{Handler: http.HandlerFunc(s.serveWebsocket)}
+	go s.server.Serve(listener)
+	return nil
+}
+
+// Close stops the server
+func (s *Server) Close() error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Close()
+}
+
+// Sessions returns the number of recorded sessions
+func (s *Server) Sessions() int {
+	return len(s.sessions)
+}
+
+// nextSession returns the recorded session with the id, if any, and otherwise the first unused session
+func (s *Server) nextSession(id string) *session {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var next *session
+	for _, session := range s.sessions {
+		if session.used {
+			continue
+		}
+		if session.id == id && id != "" {
+			next = session
+			break
+		}
+		if next == nil {
+			next = session
+		}
+	}
+	if next != nil {
+		next.used = true
+	}
+	return next
+}
+
+func (s *Server) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	session := s.nextSession(r.Header.Get("X-Qlik-Session"))
+	if session == nil {
+		http.Error(w, "no more recorded sessions", http.StatusServiceUnavailable)
+		return
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	for _, notification := range session.greeting {
+		conn.WriteMessage(websocket.TextMessage, notification)
+	}
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		request := &rpcMessage{}
+		if err := json.Unmarshal(message, request); err != nil {
+			continue
+		}
+		exchange := session.find(request)
+		if exchange == nil {
+			conn.WriteJSON(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      request.ID,
+				"error":   map[string]interface{}{"code": -1, "message": fmt.Sprintf("no recorded response to %s on handle %d", request.Method, request.Handle)},
+			})
+			continue
+		}
+		conn.WriteMessage(websocket.TextMessage, withID(exchange.response, request.ID))
+		for _, notification := range exchange.notifications {
+			conn.WriteMessage(websocket.TextMessage, notification)
+		}
+	}
+}
+
+// find returns the first unused exchange with the same method, handle and parameters as the request. If there
+// is none an exchange with the same method and handle is used, since parameters like ids may differ between runs.
+func (s *session) find(request *rpcMessage) *exchange {
+	var sameMethod *exchange
+	for _, exchange := range s.exchanges {
+		if exchange.used || exchange.response == nil || exchange.method != request.Method || exchange.handle != request.Handle {
+			continue
+		}
+		if reflect.DeepEqual(exchange.params, request.Params) {
+			exchange.used = true
+			return exchange
+		}
+		if sameMethod == nil {
+			sameMethod = exchange
+		}
+	}
+	if sameMethod != nil {
+		sameMethod.used = true
+	}
+	return sameMethod
+}
+
+// withID replaces the id of the recorded response with the id of the request
+func withID(response json.RawMessage, id json.RawMessage) []byte {
+	message := map[string]json.RawMessage{}
+	if err := json.Unmarshal(response, &message); err != nil {
+		return response
+	}
+	message["id"] = id
+	result, _ := json.Marshal(message)
+	return result
+}
@@ -0,0 +1,45 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/qlik-oss/enigma-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func record(session, direction, message string) *log.TrafficRecord {
+	return &log.TrafficRecord{Session: session, Direction: direction, Message: json.RawMessage(message)}
+}
+
+func TestReplay(t *testing.T) {
+	server := NewServer([]*log.TrafficRecord{
+		{Session: "s1", Direction: "opened"},
+		record("s1", "received", `{"jsonrpc":"2.0","method":"OnConnected","params":{"qSessionState":"SESSION_CREATED"}}`),
+		record("s1", "sent", `{"jsonrpc":"2.0","delta":false,"method":"EngineVersion","handle":-1,"id":1,"params":[]}`),
+		record("s1", "received", `{"jsonrpc":"2.0","id":1,"result":{"qVersion":{"qComponentVersion":"12.1.0"}}}`),
+		record("s1", "sent", `{"jsonrpc":"2.0","delta":false,"method":"IsDesktopMode","handle":-1,"id":2,"params":[]}`),
+		record("s1", "received", `{"jsonrpc":"2.0","id":2,"result":{"qReturn":false}}`),
+	})
+	assert.NoError(t, server.Listen("localhost:0"))
+	defer server.Close()
+	assert.Equal(t, 1, server.Sessions())
+
+	ctx := context.Background()
+	global, err := (&enigma.Dialer{}).Dial(ctx, server.URL, nil)
+	assert.NoError(t, err)
+	defer global.DisconnectFromServer()
+
+	// The requests are matched by method and not by order or id
+	desktop, err := global.IsDesktopMode(ctx)
+	assert.NoError(t, err)
+	assert.False(t, desktop)
+	version, err := global.EngineVersion(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "12.1.0", version.ComponentVersion)
+
+	_, err = global.GetDocList(ctx)
+	assert.Error(t, err)
+}
@@ -1,9 +1,11 @@
 package rest
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	neturl "net/url"
 	"os"
@@ -46,6 +48,62 @@ func ImportApp(appPath string, engine *neturl.URL, headers http.Header, certs *t
 	return
 }
 
+// ExportApp downloads the app from the engine using the rest api and writes it to out. If noData is set the app
+// is exported without data. The progress is reported with the number of bytes written and the total size, which is
+// -1 if the engine does not tell the size. An error is returned if fewer bytes than the size are received, or if
+// the context is done before the whole app is received.
+func ExportApp(ctx context.Context, appID string, noData bool, engine *neturl.URL, headers http.Header, certs *tls.Config, out io.Writer, progress func(written, total int64)) (written int64, err error) {
+	url := CreateBaseURL(*engine)
+	url.Path = fmt.Sprintf("/v1/apps/%s/export", adaptAppID(appID))
+	values := neturl.Values{}
+	if noData {
+		values.Set("NoData", "true")
+	}
+	url.RawQuery = values.Encode()
+	req := &http.Request{
+		Method: "GET",
+		URL:    url,
+		Header: headers,
+	}
+	req = req.WithContext(ctx)
+	statusCodes := &map[int]bool{
+		200: true,
+	}
+	response, err := Do(req, certs, statusCodes)
+	if err != nil {
+		err = fmt.Errorf("could not export app: %s", err.Error())
+		return
+	}
+	defer response.Body.Close()
+	total := response.ContentLength
+	written, err = io.Copy(out, &progressReader{reader: response.Body, total: total, progress: progress})
+	if err != nil {
+		err = fmt.Errorf("could not export app: %s", err.Error())
+		return
+	}
+	if total >= 0 && written != total {
+		err = fmt.Errorf("could not export app: got %d of %d bytes", written, total)
+	}
+	return
+}
+
+// progressReader reports the number of bytes read so far
+type progressReader struct {
+	reader   io.Reader
+	read     int64
+	total    int64
+	progress func(read, total int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+	if n > 0 && r.progress != nil {
+		r.progress(r.read, r.total)
+	}
+	return n, err
+}
+
 type RestNxApp struct {
 	Attributes map[string]interface{} `json:"attributes"`
 }
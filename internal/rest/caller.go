@@ -28,6 +28,24 @@ func CreateBaseURL(u neturl.URL) *neturl.URL {
 // If the response status code is not explicitly added to the map of accepted status codes
 // an error will be returned.
 func Call(req *http.Request, certs *tls.Config, result interface{}, statusCodes *map[int]bool, read parseFunction) error {
+	response, err := Do(req, certs, statusCodes)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	data, _ := ioutil.ReadAll(response.Body)
+	err = read(data, result)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Do performs the specified request and returns the response, which body must be closed by the caller,
+// so that large responses can be streamed.
+// If the response status code is not explicitly added to the map of accepted status codes
+// an error will be returned.
+func Do(req *http.Request, certs *tls.Config, statusCodes *map[int]bool) (*http.Response, error) {
 	client := http.DefaultClient
 	if certs != nil {
 		client = &http.Client{
@@ -38,19 +56,14 @@ func Call(req *http.Request, certs *tls.Config, result interface{}, statusCodes
 	}
 	response, err := client.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer response.Body.Close()
 	ok := (*statusCodes)[response.StatusCode]
 	if !ok {
-		return fmt.Errorf("got status %d", response.StatusCode)
+		response.Body.Close()
+		return nil, fmt.Errorf("got status %d", response.StatusCode)
 	}
-	data, _ := ioutil.ReadAll(response.Body)
-	err = read(data, result)
-	if err != nil {
-		return err
-	}
-	return nil
+	return response, nil
 }
 
 // Removes path and query escapes an app id.
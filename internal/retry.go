@@ -0,0 +1,289 @@
+package internal
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/qlik-oss/corectl/internal/log"
+	"github.com/qlik-oss/enigma-go"
+	"github.com/spf13/viper"
+)
+
+// RetryPolicy tells how many times, and how often, failed engine calls are retried
+type RetryPolicy struct {
+	Retries int
+	// Backoff is the time to wait before the first retry, it is doubled for every retry up to maxBackoff
+	Backoff time.Duration
+}
+
+const maxBackoff = 30 * time.Second
+
+// errConnectionLost is returned for requests that were sent but could not be resent when the connection dropped
+var errConnectionLost = errors.New("the connection to the engine was lost")
+
+// getRetryPolicy reads the retry policy from the retries and retry-backoff flags
+func getRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{Retries: viper.GetInt("retries"), Backoff: viper.GetDuration("retry-backoff")}
+}
+
+// wait sleeps before the retry with the given number, starting at 1. It returns false if the context is done.
+func (p *RetryPolicy) wait(ctx context.Context, retry int) bool {
+	backoff := p.Backoff
+	for i := 1; i < retry && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	select {
+	case <-time.After(backoff):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// do calls f until it succeeds, returns an error that is not transient or the retries run out. Retries are
+// logged with the description of the call.
+func (p *RetryPolicy) do(ctx context.Context, description string, transient func(error) bool, f func() error) error {
+	err := f()
+	for retry := 1; err != nil && retry <= p.Retries && transient(err); retry++ {
+		log.Warnf("Retrying %s (%d of %d) after error: %s\n", description, retry, p.Retries, err)
+		if !p.wait(ctx, retry) {
+			return err
+		}
+		err = f()
+	}
+	return err
+}
+
+// retrySet retries setting an entity if the connection was lost. Setting an entity is idempotent, it is
+// created if missing and updated otherwise.
+func retrySet(ctx context.Context, kind, id string, set func() error) error {
+	return getRetryPolicy().do(ctx, kind+" "+id, isConnectionLost, set)
+}
+
+// isConnectionLost tells whether the error is caused by a request that was lost when the connection dropped
+func isConnectionLost(err error) bool {
+	return err != nil && strings.Contains(err.Error(), errConnectionLost.Error())
+}
+
+// isTransientConnectError tells whether connecting may succeed if retried, unlike when credentials or
+// certificates are rejected
+func isTransientConnectError(err error) bool {
+	message := err.Error()
+	return !strings.Contains(message, "401") && !strings.Contains(message, "403") && !strings.Contains(message, "x509")
+}
+
+// reconnectingSocket is an enigma socket that re-attaches to the same engine session, using the same
+// X-Qlik-Session header, when the connection drops. Requests that did not get a response are resent if they are
+// idempotent and fail with errConnectionLost otherwise. The engine only keeps the session, and thus the
+// handles, if it has a ttl.
+type reconnectingSocket struct {
+	ctx     context.Context
+	url     string
+	headers http.Header
+	dialer  *websocket.Dialer
+	policy  *RetryPolicy
+
+	mutex  sync.Mutex
+	socket *websocket.Conn
+	closed bool
+	// inFlight are the requests that have not got a response, by id
+	inFlight map[string]*inFlightRequest
+	// responses are messages to return before reading from the socket
+	responses [][]byte
+}
+
+type inFlightRequest struct {
+	method  string
+	message []byte
+}
+
+type socketMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+}
+
+// idempotentMethods are resent when the connection drops, besides all Get methods
+var idempotentMethods = map[string]bool{
+	"SetProperties":        true,
+	"SetFullPropertyTree":  true,
+	"SetScript":            true,
+	"SetAppProperties":     true,
+	"SetScriptBreakpoints": true,
+	"SetFetchLimit":        true,
+	"Evaluate":             true,
+	"EvaluateEx":           true,
+	"EngineVersion":        true,
+	"CheckExpression":      true,
+}
+
+func isIdempotent(method string) bool {
+	return strings.HasPrefix(method, "Get") || idempotentMethods[method]
+}
+
+// newReconnectingSocketCreator returns a CreateSocket function for the enigma dialer
+func newReconnectingSocketCreator(tlsClientConfig *tls.Config, policy *RetryPolicy) func(context.Context, string, http.Header) (enigma.Socket, error) {
+	return func(ctx context.Context, url string, headers http.Header) (enigma.Socket, error) {
+		s := &reconnectingSocket{
+			ctx:      ctx,
+			url:      url,
+			headers:  headers,
+			policy:   policy,
+			inFlight: map[string]*inFlightRequest{},
+			dialer: &websocket.Dialer{
+				TLSClientConfig: tlsClientConfig,
+				NetDial: func(network, addr string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, network, addr)
+				},
+			},
+		}
+		socket, err := s.dial()
+		if err != nil {
+			return nil, err
+		}
+		s.socket = socket
+		return s, nil
+	}
+}
+
+func (s *reconnectingSocket) dial() (*websocket.Conn, error) {
+	socket, response, err := s.dialer.Dial(s.url, s.headers)
+	if err == websocket.ErrBadHandshake {
+		err = fmt.Errorf("%s: %d from ws server", err, response.StatusCode)
+	}
+	return socket, err
+}
+
+// WriteMessage implements the enigma Socket interface
+func (s *reconnectingSocket) WriteMessage(messageType int, data []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	message := &socketMessage{}
+	if json.Unmarshal(data, message) == nil && len(message.ID) > 0 {
+		s.inFlight[string(message.ID)] = &inFlightRequest{method: message.Method, message: data}
+	}
+	if err := s.socket.WriteMessage(messageType, data); err != nil && s.closed {
+		return err
+	}
+	// A failed write is handled when reading fails and the request is then resent or failed
+	return nil
+}
+
+// ReadMessage implements the enigma Socket interface
+func (s *reconnectingSocket) ReadMessage() (int, []byte, error) {
+	for {
+		s.mutex.Lock()
+		if len(s.responses) > 0 {
+			response := s.responses[0]
+			s.responses = s.responses[1:]
+			s.mutex.Unlock()
+			return websocket.TextMessage, response, nil
+		}
+		socket := s.socket
+		s.mutex.Unlock()
+
+		messageType, data, err := socket.ReadMessage()
+		if err == nil {
+			message := &socketMessage{}
+			if json.Unmarshal(data, message) == nil && len(message.ID) > 0 {
+				s.mutex.Lock()
+				delete(s.inFlight, string(message.ID))
+				s.mutex.Unlock()
+			}
+			return messageType, data, nil
+		}
+		s.mutex.Lock()
+		closed := s.closed
+		s.mutex.Unlock()
+		if closed || !s.reconnect(err) {
+			return messageType, data, err
+		}
+	}
+}
+
+// reconnect re-attaches to the session and resends or fails the requests in flight
+func (s *reconnectingSocket) reconnect(cause error) bool {
+	log.Warnf("Lost the connection to the engine: %s\n", cause)
+	var socket *websocket.Conn
+	err := s.policy.do(s.ctx, "connection to the engine", func(err error) bool { return err != errSessionNotAttached }, func() error {
+		var err error
+		socket, err = s.dial()
+		if err != nil {
+			return err
+		}
+		if err = readAttachedMessage(socket); err != nil {
+			socket.Close()
+		}
+		return err
+	})
+	if err != nil {
+		log.Errorf("could not re-attach to the engine session: %s\n", err)
+		return false
+	}
+	log.Warnln("Re-attached to the engine session")
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.socket.Close()
+	s.socket = socket
+	for id, request := range s.inFlight {
+		if isIdempotent(request.method) && socket.WriteMessage(websocket.TextMessage, request.message) == nil {
+			log.Verboseln("Resending " + request.method)
+			continue
+		}
+		delete(s.inFlight, id)
+		s.responses = append(s.responses, connectionLostResponse(id, request.method))
+	}
+	return true
+}
+
+var errSessionNotAttached = errors.New("the engine did not keep the session, set --ttl to keep sessions alive when disconnected")
+
+// readAttachedMessage reads the OnConnected notification of a new connection and checks that it re-attached
+// to the session
+func readAttachedMessage(socket *websocket.Conn) error {
+	_, data, err := socket.ReadMessage()
+	if err != nil {
+		return err
+	}
+	notification := &struct {
+		Method string            `json:"method"`
+		Params map[string]string `json:"params"`
+	}{}
+	json.Unmarshal(data, notification)
+	switch {
+	case notification.Method != "OnConnected":
+		return fmt.Errorf("unexpected message from engine: %s", data)
+	case notification.Params["qSessionState"] != "SESSION_ATTACHED":
+		return errSessionNotAttached
+	}
+	return nil
+}
+
+func connectionLostResponse(id, method string) []byte {
+	response, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      json.RawMessage(id),
+		"error":   map[string]interface{}{"code": -1, "parameter": method, "message": errConnectionLost.Error()},
+	})
+	return response
+}
+
+// Close implements the enigma Socket interface
+func (s *reconnectingSocket) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+	return s.socket.Close()
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qlik-oss/corectl/test/fakeengine"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func startRetryEngine(t *testing.T, ttl string) (*fakeengine.Engine, *State) {
+	engine := fakeengine.New()
+	assert.Nil(t, engine.Start())
+	engine.AddApp("app")
+	viper.Set("engine", engine.Address())
+	viper.Set("app", "app")
+	viper.Set("ttl", ttl)
+	viper.Set("retries", 2)
+	viper.Set("retry-backoff", 10*time.Millisecond)
+	state := PrepareEngineState(context.Background(), http.Header{}, nil, false, false)
+	return engine, state
+}
+
+func resetRetryConfig() {
+	for _, key := range []string{"engine", "app", "ttl", "retries", "retry-backoff"} {
+		viper.Set(key, nil)
+	}
+}
+
+func TestReattachAfterDroppedConnection(t *testing.T) {
+	defer resetRetryConfig()
+	engine, state := startRetryEngine(t, "60")
+	defer engine.Close()
+	ctx := state.Ctx
+
+	assert.Nil(t, state.Doc.SetScript(ctx, "Load 1 as a AutoGenerate 1;"))
+	engine.DropConnections()
+	script, err := state.Doc.GetScript(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "Load 1 as a AutoGenerate 1;", script)
+
+	// Idempotent requests in flight are resent
+	dropped := false
+	engine.Handle("GetScript", func(session *fakeengine.Session, handle int, params []json.RawMessage) (interface{}, error) {
+		if !dropped {
+			dropped = true
+			session.Disconnect()
+		}
+		return nil, fakeengine.ErrNotHandled
+	})
+	script, err = state.Doc.GetScript(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "Load 1 as a AutoGenerate 1;", script)
+	assert.True(t, dropped)
+}
+
+func TestRetrySetAfterDroppedConnection(t *testing.T) {
+	defer resetRetryConfig()
+	engine, state := startRetryEngine(t, "60")
+	defer engine.Close()
+
+	dropped := false
+	engine.Handle("CreateMeasure", func(session *fakeengine.Session, handle int, params []json.RawMessage) (interface{}, error) {
+		if !dropped {
+			dropped = true
+			session.Disconnect()
+			return nil, nil
+		}
+		return nil, fakeengine.ErrNotHandled
+	})
+	dir, err := ioutil.TempDir("", "corectl")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "measures.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"qInfo":{"qId":"m1","qType":"measure"},"qMeasure":{"qDef":"Sum(a)"}}`), 0644))
+
+	SetMeasures(state.Ctx, state.Doc, path)
+	assert.True(t, dropped)
+	assert.NotNil(t, engine.Apps["app"].Entities["m1"])
+}
+
+func TestNoReattachWithoutTTL(t *testing.T) {
+	defer resetRetryConfig()
+	engine, state := startRetryEngine(t, "0")
+	defer engine.Close()
+
+	engine.DropConnections()
+	_, err := state.Doc.GetScript(state.Ctx)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,179 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonSchema is the subset of JSON Schema used by the bundled object schemas
+type jsonSchema struct {
+	Ref        string                 `json:"$ref"`
+	AllOf      []*jsonSchema          `json:"allOf"`
+	If         *jsonSchema            `json:"if"`
+	Then       *jsonSchema            `json:"then"`
+	Else       *jsonSchema            `json:"else"`
+	Type       interface{}            `json:"type"`
+	Required   []string               `json:"required"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Items      *jsonSchema            `json:"items"`
+	Enum       []interface{}          `json:"enum"`
+	MinLength  *int                   `json:"minLength"`
+	MinItems   *int                   `json:"minItems"`
+	Minimum    *float64               `json:"minimum"`
+}
+
+// schemaError is a validation error along with the JSON pointer of the invalid value
+type schemaError struct {
+	Pointer string
+	Message string
+}
+
+// schemaSet is a set of schemas where '#/definitions/<name>' refers to the schema with that name
+type schemaSet map[string]*jsonSchema
+
+func parseSchemas(sources map[string]string) schemaSet {
+	schemas := schemaSet{}
+	for name, source := range sources {
+		schema := &jsonSchema{}
+		if err := json.Unmarshal([]byte(source), schema); err != nil {
+			panic(fmt.Sprintf("invalid bundled schema '%s': %s", name, err))
+		}
+		schemas[name] = schema
+	}
+	return schemas
+}
+
+// validate checks the value against the schema and returns all errors found
+func (s schemaSet) validate(schema *jsonSchema, value interface{}, pointer string) []schemaError {
+	errors := []schemaError{}
+	fail := func(format string, a ...interface{}) {
+		errors = append(errors, schemaError{Pointer: pointer, Message: fmt.Sprintf(format, a...)})
+	}
+	if schema.Ref != "" {
+		ref, ok := s[strings.TrimPrefix(schema.Ref, "#/definitions/")]
+		if !ok {
+			panic("unknown schema reference " + schema.Ref)
+		}
+		errors = append(errors, s.validate(ref, value, pointer)...)
+	}
+	for _, sub := range schema.AllOf {
+		errors = append(errors, s.validate(sub, value, pointer)...)
+	}
+	if schema.If != nil {
+		branch := schema.Else
+		if len(s.validate(schema.If, value, pointer)) == 0 {
+			branch = schema.Then
+		}
+		if branch != nil {
+			errors = append(errors, s.validate(branch, value, pointer)...)
+		}
+	}
+	if types := schemaTypes(schema.Type); len(types) > 0 && !matchesType(types, value) {
+		fail("expected %s but found %s", strings.Join(types, " or "), jsonType(value))
+		return errors
+	}
+	if len(schema.Enum) > 0 {
+		found := false
+		for _, allowed := range schema.Enum {
+			if allowed == value {
+				found = true
+			}
+		}
+		if !found {
+			fail("%s is not one of %s", marshalToString(value), marshalToString(schema.Enum))
+		}
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				fail("missing required property '%s'", name)
+			}
+		}
+		names := []string{}
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := v[name]; ok {
+				errors = append(errors, s.validate(schema.Properties[name], property, pointer+"/"+escapePointer(name))...)
+			}
+		}
+	case []interface{}:
+		if schema.MinItems != nil && len(v) < *schema.MinItems {
+			fail("expected at least %d item(s) but found %d", *schema.MinItems, len(v))
+		}
+		if schema.Items != nil {
+			for i, item := range v {
+				errors = append(errors, s.validate(schema.Items, item, pointer+"/"+strconv.Itoa(i))...)
+			}
+		}
+	case string:
+		if schema.MinLength != nil && len(v) < *schema.MinLength {
+			fail("expected a string of at least %d character(s)", *schema.MinLength)
+		}
+	case float64:
+		if schema.Minimum != nil && v < *schema.Minimum {
+			fail("expected a number of at least %v but found %v", *schema.Minimum, v)
+		}
+	}
+	return errors
+}
+
+func schemaTypes(schemaType interface{}) []string {
+	switch t := schemaType.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := []string{}
+		for _, x := range t {
+			types = append(types, fmt.Sprint(x))
+		}
+		return types
+	}
+	return nil
+}
+
+func matchesType(types []string, value interface{}) bool {
+	actual := jsonType(value)
+	for _, t := range types {
+		if t == actual || t == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+func marshalToString(value interface{}) string {
+	bytes, _ := json.Marshal(value)
+	return string(bytes)
+}
+
+// escapePointer escapes a property name for use in a JSON pointer
+func escapePointer(name string) string {
+	return strings.Replace(strings.Replace(name, "~", "~0", -1), "/", "~1", -1)
+}
@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/qlik-oss/corectl/internal/log"
+)
+
+type (
+	// ModelSnapshot is a summary of the data model saved after a reload to detect regressions in later reloads.
+	// The byte sizes are only saved for reference and are not compared.
+	ModelSnapshot struct {
+		App          string                 `json:"app"`
+		Created      string                 `json:"created"`
+		Tables       []*TableSnapshot       `json:"tables"`
+		Fields       []*FieldSnapshot       `json:"fields"`
+		Associations []*AssociationSnapshot `json:"associations"`
+	}
+
+	// TableSnapshot is a table in a ModelSnapshot
+	TableSnapshot struct {
+		Name     string   `json:"name"`
+		Rows     int      `json:"rows"`
+		ByteSize int      `json:"byteSize,omitempty"`
+		Fields   []string `json:"fields"`
+	}
+
+	// FieldSnapshot is a field in a ModelSnapshot
+	FieldSnapshot struct {
+		Name       string `json:"name"`
+		Cardinal   int    `json:"cardinal"`
+		TotalCount int    `json:"totalCount"`
+		ByteSize   int    `json:"byteSize"`
+		Key        bool   `json:"key,omitempty"`
+	}
+
+	// AssociationSnapshot is a key associating tables in a ModelSnapshot
+	AssociationSnapshot struct {
+		KeyFields []string `json:"keyFields"`
+		Tables    []string `json:"tables"`
+	}
+)
+
+// Severities of the differences found when comparing snapshots
+var snapshotSeverities = map[string]string{
+	"table-removed":    "error",
+	"row-drop":         "error",
+	"key-changed":      "error",
+	"field-removed":    "warning",
+	"cardinality-drop": "warning",
+	"table-added":      "info",
+	"field-added":      "info",
+}
+
+// CreateModelSnapshot summarizes the data model
+func CreateModelSnapshot(appName string, data *ModelMetadata) *ModelSnapshot {
+	snapshot := &ModelSnapshot{
+		App:          appName,
+		Created:      time.Now().UTC().Format(time.RFC3339),
+		Tables:       []*TableSnapshot{},
+		Fields:       []*FieldSnapshot{},
+		Associations: []*AssociationSnapshot{},
+	}
+	for _, table := range data.Tables {
+		tableSnapshot := &TableSnapshot{Name: table.Name, Rows: table.NoOfRows, Fields: []string{}}
+		if table.RestMetadata != nil {
+			tableSnapshot.ByteSize = table.RestMetadata.ByteSize
+		}
+		for _, field := range table.Fields {
+			tableSnapshot.Fields = append(tableSnapshot.Fields, field.Name)
+		}
+		snapshot.Tables = append(snapshot.Tables, tableSnapshot)
+	}
+	for _, field := range data.Fields {
+		if field.FieldDescription == nil || field.IsSystem {
+			continue
+		}
+		snapshot.Fields = append(snapshot.Fields, &FieldSnapshot{
+			Name:       field.Name,
+			Cardinal:   field.Cardinal,
+			TotalCount: field.TotalCount,
+			ByteSize:   field.ByteSize,
+			Key:        isKey(field),
+		})
+	}
+	for _, key := range data.SourceKeys {
+		tables := append([]string{}, key.Tables...)
+		sort.Strings(tables)
+		snapshot.Associations = append(snapshot.Associations, &AssociationSnapshot{KeyFields: key.KeyFields, Tables: tables})
+	}
+	return snapshot
+}
+
+// WriteModelSnapshot saves the snapshot as json
+func WriteModelSnapshot(path string, snapshot *ModelSnapshot) {
+	if err := ioutil.WriteFile(path, marshalOrFail(snapshot), 0644); err != nil {
+		log.Fatalf("could not write snapshot file '%s': %s\n", path, err)
+	}
+	log.Infoln("Saved snapshot to " + path)
+}
+
+// ReadModelSnapshot reads a snapshot created by 'meta snapshot'
+func ReadModelSnapshot(path string) *ModelSnapshot {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Fatalf("could not read snapshot file '%s': %s\n", path, err)
+	}
+	snapshot := &ModelSnapshot{}
+	if err := json.Unmarshal(content, snapshot); err != nil {
+		log.Fatalf("could not parse snapshot file '%s': %s\n", path, err)
+	}
+	return snapshot
+}
+
+// CompareModelSnapshots reports the differences from the saved snapshot to the current one. Removed tables,
+// tables where the number of rows dropped by more than maxRowDrop percent and changed keys are errors. Fields
+// where the number of distinct values dropped by more than maxRowDrop percent are warnings.
+func CompareModelSnapshots(saved, current *ModelSnapshot, maxRowDrop float64) []*LintFinding {
+	findings := []*LintFinding{}
+	add := func(rule, table, field, format string, a ...interface{}) {
+		findings = append(findings, &LintFinding{Rule: rule, Severity: snapshotSeverities[rule], Table: table, Field: field, Message: fmt.Sprintf(format, a...)})
+	}
+
+	currentTables := map[string]*TableSnapshot{}
+	for _, table := range current.Tables {
+		currentTables[table.Name] = table
+	}
+	savedTables := map[string]bool{}
+	for _, table := range saved.Tables {
+		savedTables[table.Name] = true
+		currentTable, exists := currentTables[table.Name]
+		if !exists {
+			add("table-removed", table.Name, "", "the table with %d rows is missing", table.Rows)
+			continue
+		}
+		if drop := percentDrop(table.Rows, currentTable.Rows); drop > maxRowDrop {
+			add("row-drop", table.Name, "", "the number of rows dropped by %.1f%% from %d to %d", drop, table.Rows, currentTable.Rows)
+		}
+	}
+	for _, table := range current.Tables {
+		if !savedTables[table.Name] {
+			add("table-added", table.Name, "", "new table with %d rows", table.Rows)
+		}
+	}
+
+	currentFields := map[string]*FieldSnapshot{}
+	for _, field := range current.Fields {
+		currentFields[field.Name] = field
+	}
+	savedFields := map[string]bool{}
+	for _, field := range saved.Fields {
+		savedFields[field.Name] = true
+		currentField, exists := currentFields[field.Name]
+		switch {
+		case !exists && field.Key:
+			add("key-changed", "", field.Name, "the key field is missing")
+		case !exists:
+			add("field-removed", "", field.Name, "the field is missing")
+		case field.Key && !currentField.Key:
+			add("key-changed", "", field.Name, "the field is no longer a key")
+		case !field.Key && currentField.Key:
+			add("key-changed", "", field.Name, "the field has become a key")
+		case percentDrop(field.Cardinal, currentField.Cardinal) > maxRowDrop:
+			add("cardinality-drop", "", field.Name, "the number of distinct values dropped by %.1f%% from %d to %d",
+				percentDrop(field.Cardinal, currentField.Cardinal), field.Cardinal, currentField.Cardinal)
+		}
+	}
+	for _, field := range current.Fields {
+		if !savedFields[field.Name] {
+			add("field-added", "", field.Name, "new field")
+		}
+	}
+
+	savedAssociations := associationSet(saved.Associations)
+	currentAssociations := associationSet(current.Associations)
+	for _, association := range sortedKeys(savedAssociations) {
+		if _, exists := currentAssociations[association]; !exists {
+			add("key-changed", "", "", "the association %s is missing", association)
+		}
+	}
+	for _, association := range sortedKeys(currentAssociations) {
+		if _, exists := savedAssociations[association]; !exists {
+			add("key-changed", "", "", "new association %s", association)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityLevel(findings[i].Severity) > severityLevel(findings[j].Severity)
+	})
+	return findings
+}
+
+// percentDrop returns how many percent lower the current count is than the saved one, or 0 if nothing was saved
+func percentDrop(saved, current int) float64 {
+	if saved <= 0 {
+		return 0
+	}
+	return float64(saved-current) / float64(saved) * 100
+}
+
+func associationSet(associations []*AssociationSnapshot) map[string]string {
+	set := map[string]string{}
+	for _, association := range associations {
+		description := strings.Join(association.KeyFields, "+") + " between " + strings.Join(association.Tables, ", ")
+		set[description] = description
+	}
+	return set
+}
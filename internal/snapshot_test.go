@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/qlik-oss/enigma-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateModelSnapshot(t *testing.T) {
+	data := &ModelMetadata{
+		Tables: []*TableModel{{TableRecord: &enigma.TableRecord{Name: "Orders", NoOfRows: 10, Fields: []*enigma.FieldInTableData{{Name: "Id"}}}}},
+		Fields: []*FieldModel{
+			{FieldDescription: &enigma.FieldDescription{Name: "Id", Cardinal: 10, TotalCount: 10}},
+			{FieldDescription: &enigma.FieldDescription{Name: "$Table", IsSystem: true}},
+			{},
+		},
+	}
+	snapshot := CreateModelSnapshot("sales", data)
+	assert.Equal(t, []*TableSnapshot{{Name: "Orders", Rows: 10, Fields: []string{"Id"}}}, snapshot.Tables)
+	assert.Equal(t, []*FieldSnapshot{{Name: "Id", Cardinal: 10, TotalCount: 10}}, snapshot.Fields)
+}
+
+func TestCompareModelSnapshots(t *testing.T) {
+	saved := &ModelSnapshot{
+		Tables: []*TableSnapshot{{Name: "Orders", Rows: 1000}, {Name: "Customers", Rows: 100}, {Name: "Regions", Rows: 5}},
+		Fields: []*FieldSnapshot{{Name: "CustomerId", Key: true}, {Name: "Region", Key: true}, {Name: "Comment"}, {Name: "Product", Cardinal: 50}},
+		Associations: []*AssociationSnapshot{
+			{KeyFields: []string{"CustomerId"}, Tables: []string{"Customers", "Orders"}},
+			{KeyFields: []string{"Region"}, Tables: []string{"Customers", "Regions"}},
+		},
+	}
+	current := &ModelSnapshot{
+		Tables: []*TableSnapshot{{Name: "Orders", Rows: 950}, {Name: "Customers", Rows: 80}, {Name: "Products", Rows: 10}},
+		Fields: []*FieldSnapshot{{Name: "CustomerId", Key: true}, {Name: "Region"}, {Name: "Product", Cardinal: 40}},
+		Associations: []*AssociationSnapshot{
+			{KeyFields: []string{"CustomerId"}, Tables: []string{"Customers", "Orders"}},
+		},
+	}
+	result := []string{}
+	for _, finding := range CompareModelSnapshots(saved, current, 10) {
+		result = append(result, finding.Severity+" "+finding.Rule+" "+finding.Table+finding.Field+" "+finding.Message)
+	}
+	assert.Equal(t, []string{
+		"error row-drop Customers the number of rows dropped by 20.0% from 100 to 80",
+		"error table-removed Regions the table with 5 rows is missing",
+		"error key-changed Region the field is no longer a key",
+		"error key-changed  the association Region between Customers, Regions is missing",
+		"warning field-removed Comment the field is missing",
+		"warning cardinality-drop Product the number of distinct values dropped by 20.0% from 50 to 40",
+		"info table-added Products new table with 10 rows",
+	}, result)
+}
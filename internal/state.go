@@ -43,8 +43,8 @@ func logConnectError(err error, engine string) {
 	log.Fatalln(msg)
 }
 
-func connectToEngine(ctx context.Context, appName, ttl string, headers http.Header, tlsClientConfig *tls.Config) *enigma.Global {
-	engineURL := buildWebSocketURL(ttl)
+func connectToEngine(ctx context.Context, engine, appName, ttl string, headers http.Header, tlsClientConfig *tls.Config) *enigma.Global {
+	engineURL := buildWebSocketURL(engine, ttl)
 	log.Verboseln("Engine: " + engineURL)
 
 	if headers.Get("X-Qlik-Session") == "" {
@@ -56,11 +56,18 @@ func connectToEngine(ctx context.Context, appName, ttl string, headers http.Head
 	var dialer = enigma.Dialer{}
 	dialer.TLSClientConfig = tlsClientConfig
 
-	if log.Traffic {
-		dialer.TrafficLogger = log.TrafficLogger{}
-	}
+	dialer.TrafficLogger = log.NewTrafficLogger(headers.Get("X-Qlik-Session"), engineURL)
 
-	global, err := dialer.Dial(ctx, engineURL, headers)
+	policy := getRetryPolicy()
+	if policy.Retries > 0 {
+		dialer.CreateSocket = newReconnectingSocketCreator(tlsClientConfig, policy)
+	}
+	var global *enigma.Global
+	err := policy.do(ctx, "connection to "+engineURL, isTransientConnectError, func() error {
+		var err error
+		global, err = dialer.Dial(ctx, engineURL, headers)
+		return err
+	})
 	if err != nil {
 		logConnectError(err, engineURL)
 	}
@@ -99,8 +106,11 @@ func DeleteApp(ctx context.Context, engine string, appName string, headers http.
 // Any ttl supplied (through viper) specifies how long the engine should keep the session alive which affects
 // performance. (It is cheaper to reattach to a pre-existing session, performance-wise.)
 func PrepareEngineState(ctx context.Context, headers http.Header, tlsClientConfig *tls.Config, createAppIfMissing, withoutApp bool) *State {
-	engine := viper.GetString("engine")
-	appName := viper.GetString("app")
+	return prepareEngineState(ctx, viper.GetString("engine"), viper.GetString("app"), headers, tlsClientConfig, createAppIfMissing, withoutApp)
+}
+
+// prepareEngineState is PrepareEngineState with the engine and app given explicitly instead of through viper
+func prepareEngineState(ctx context.Context, engine, appName string, headers http.Header, tlsClientConfig *tls.Config, createAppIfMissing, withoutApp bool) *State {
 	ttl := viper.GetString("ttl")
 	noData := viper.GetBool("no-data")
 
@@ -117,7 +127,7 @@ func PrepareEngineState(ctx context.Context, headers http.Header, tlsClientConfi
 	}
 
 	log.Verboseln("---------- Connecting to engine ----------")
-	global := connectToEngine(ctx, appName, ttl, headers, tlsClientConfig)
+	global := connectToEngine(ctx, engine, appName, ttl, headers, tlsClientConfig)
 	sessionMessages := global.SessionMessageChannel()
 	err := waitForOnConnectedMessage(sessionMessages)
 	if err != nil {
@@ -126,7 +136,7 @@ func PrepareEngineState(ctx context.Context, headers http.Header, tlsClientConfi
 	go printSessionMessagesIfInVerboseMode(sessionMessages)
 
 	if !withoutApp {
-		appID, _ = applyNameToIDTransformation(appName)
+		appID, _ = knownAppID(toEngineURL(engine), appName)
 		doc, _ = global.GetActiveDoc(ctx)
 		if doc != nil {
 			// There is an already opened doc!
@@ -172,6 +182,42 @@ func PrepareEngineState(ctx context.Context, headers http.Header, tlsClientConfi
 	}
 }
 
+// PrepareOtherEngineState connects to a second app while keeping the current configuration intact. The app
+// defaults to the current app and the engine to the current engine. If a context is given its engine,
+// headers and certificates are used instead of the current ones, except for the User-Agent.
+func PrepareOtherEngineState(ctx context.Context, headers http.Header, tlsClientConfig *tls.Config, appName, engine, contextName string) *State {
+	if contextName != "" {
+		context := NewContextHandler().Get(contextName)
+		if context == nil {
+			log.Fatalf("context with name '%s' does not exist\n", contextName)
+		}
+		if engine == "" {
+			engine = context.Engine
+		}
+		userAgent := headers.Get("User-Agent")
+		headers = make(http.Header, len(context.Headers))
+		for key, value := range context.Headers {
+			headers.Set(key, value)
+		}
+		if userAgent != "" && headers.Get("User-Agent") == "" {
+			headers.Set("User-Agent", userAgent)
+		}
+		if context.Certificates != "" {
+			tlsClientConfig = ReadCertificates(&tls.Config{InsecureSkipVerify: tlsClientConfig.InsecureSkipVerify}, context.Certificates)
+		}
+	}
+	// Never reuse the session of the first app
+	headers.Del("X-Qlik-Session")
+
+	if engine == "" {
+		engine = viper.GetString("engine")
+	}
+	if appName == "" {
+		appName = viper.GetString("app")
+	}
+	return prepareEngineState(ctx, engine, appName, headers, tlsClientConfig, false, false)
+}
+
 func waitForOnConnectedMessage(sessionMessages chan enigma.SessionMessage) error {
 	for sessionEvent := range sessionMessages {
 		log.Verboseln(sessionEvent.Topic + " " + string(sessionEvent.Content))
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qlik-oss/corectl/test/fakeengine"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrepareOtherEngineState(t *testing.T) {
+	engine := fakeengine.New()
+	assert.Nil(t, engine.Start())
+	defer engine.Close()
+	engine.AddApp("other")
+	var header http.Header
+	engine.Handle("OpenDoc", func(session *fakeengine.Session, handle int, params []json.RawMessage) (interface{}, error) {
+		header = session.Header
+		return nil, fakeengine.ErrNotHandled
+	})
+
+	dir, err := ioutil.TempDir("", "corectl-state")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	previousKnownApps, previousContexts := knownAppsFilePath, contextFilePath
+	knownAppsFilePath, contextFilePath = filepath.Join(dir, "knownApps.yml"), filepath.Join(dir, "contexts.yml")
+	defer func() { knownAppsFilePath, contextFilePath = previousKnownApps, previousContexts }()
+	contexts := "contexts:\n  production:\n    engine: " + engine.Address() + "\n    headers:\n      X-Custom: production\n"
+	assert.NoError(t, ioutil.WriteFile(contextFilePath, []byte(contexts), 0644))
+
+	viper.Set("engine", "localhost:1")
+	viper.Set("app", "first")
+	viper.Set("ttl", "0")
+	defer func() {
+		for _, key := range []string{"engine", "app", "ttl"} {
+			viper.Set(key, nil)
+		}
+	}()
+	headers := http.Header{}
+	headers.Set("User-Agent", "corectl/test")
+	headers.Set("X-Custom", "current")
+
+	state := PrepareOtherEngineState(context.Background(), headers, nil, "other", "", "production")
+	defer state.Global.DisconnectFromServer()
+	assert.Equal(t, "other", state.AppID)
+	assert.Equal(t, "corectl/test", header.Get("User-Agent"))
+	assert.Equal(t, "production", header.Get("X-Custom"))
+	assert.Equal(t, "localhost:1", viper.GetString("engine"))
+	assert.Equal(t, "first", viper.GetString("app"))
+}
@@ -21,7 +21,7 @@ func createSystemTableHypercube(ctx context.Context, doc *enigma.Doc) *enigma.Ge
 
 	noOfLeftDims := 1
 
-	object, _ := doc.CreateSessionObject(ctx, &enigma.GenericObjectProperties{
+	object, err := doc.CreateSessionObject(ctx, &enigma.GenericObjectProperties{
 		Info: &enigma.NxInfo{
 			Type: "my-pivot-hypercube",
 		},
@@ -52,6 +52,9 @@ func createSystemTableHypercube(ctx context.Context, doc *enigma.Doc) *enigma.Ge
 			NoOfLeftDims: &noOfLeftDims,
 		},
 	})
+	if err != nil {
+		log.Fatalf("could not create session object: %s\n", err)
+	}
 
 	return object
 }
@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andreyvit/diff"
+)
+
+// Number of unchanged lines shown around each change in a unified diff
+const diffContextLines = 3
+
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// unifiedDiff returns the difference between the texts in unified diff format,
+// or an empty string if they are equal.
+func unifiedDiff(nameA, nameB, a, b string) string {
+	if a == b {
+		return ""
+	}
+	lines := []diffLine{}
+	// The lines are prefixed with ' ', '-' or '+', with the removed lines of each change before the added ones
+	for _, line := range diff.LineDiffAsLines(a, b) {
+		lines = append(lines, diffLine{line[0], line[1:]})
+	}
+
+	var result strings.Builder
+	fmt.Fprintf(&result, "--- %s\n+++ %s\n", nameA, nameB)
+	lineA, lineB := 1, 1
+	for i := 0; i < len(lines); {
+		change := i
+		for change < len(lines) && lines[change].op == ' ' {
+			change++
+		}
+		if change == len(lines) {
+			break
+		}
+		start := change - diffContextLines
+		if start < i {
+			start = i
+		}
+		lineA += start - i
+		lineB += start - i
+		// Changes separated by less than twice the context belong to the same hunk
+		end := change
+		for {
+			for end < len(lines) && lines[end].op != ' ' {
+				end++
+			}
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			if next < len(lines) && next-end <= 2*diffContextLines {
+				end = next
+				continue
+			}
+			end = minInt(end+diffContextLines, len(lines))
+			break
+		}
+		countA, countB := 0, 0
+		for _, line := range lines[start:end] {
+			if line.op != '+' {
+				countA++
+			}
+			if line.op != '-' {
+				countB++
+			}
+		}
+		fmt.Fprintf(&result, "@@ -%s +%s @@\n", hunkRange(lineA, countA), hunkRange(lineB, countB))
+		for _, line := range lines[start:end] {
+			fmt.Fprintf(&result, "%c%s\n", line.op, line.text)
+		}
+		lineA += countA
+		lineB += countB
+		i = end
+	}
+	return result.String()
+}
+
+func hunkRange(line, count int) string {
+	if count == 0 {
+		// An empty range refers to the line before it
+		line--
+	}
+	return fmt.Sprintf("%d,%d", line, count)
+}
@@ -3,6 +3,7 @@ package internal
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -57,96 +58,155 @@ func Unbuild(ctx context.Context, doc *enigma.Doc, global *enigma.Global, rootFo
 }
 
 func exportEntities(ctx context.Context, doc *enigma.Doc, folder string) {
-	measureArray := make([]JSONWithOrder, 0)
-	var measureArrayLock sync.Mutex
-	dimensionArray := make([]JSONWithOrder, 0)
-	var dimensionArrayLock sync.Mutex
-	allInfos, _ := doc.GetAllInfos(ctx)
-	waitChannel := make(chan interface{}, 10000)
-	defer close(waitChannel)
-	for index, item := range allInfos {
-		go func(index int, item *enigma.NxInfo) {
-			if dimension, _ := doc.GetDimension(ctx, item.Id); dimension != nil && dimension.Type != "" {
-				props, _ := dimension.GetPropertiesRaw(ctx)
-				dimensionArrayLock.Lock()
-				dimensionArray = append(dimensionArray, JSONWithOrder{props, index})
-				dimensionArrayLock.Unlock()
-			} else if measure, _ := doc.GetMeasure(ctx, item.Id); measure != nil && measure.Type != "" {
-				props, _ := measure.GetPropertiesRaw(ctx)
-				measureArrayLock.Lock()
-				measureArray = append(measureArray, JSONWithOrder{props, index})
-				measureArrayLock.Unlock()
-			} else if object, _ := doc.GetObject(ctx, item.Id); object != nil && object.Type != "" {
-				parent, _ := object.GetParent(ctx)
-				children, _ := object.GetChildInfos(ctx)
-				if parent.Handle == 0 {
-					var rawProps json.RawMessage
-					if len(children) > 0 {
-						rawProps, _ = object.GetFullPropertyTreeRaw(ctx)
-					} else {
-						rawProps, _ = object.GetPropertiesRaw(ctx)
-					}
-					propsWithTitle := &UnbuildEntityProperies{}
-					json.Unmarshal(rawProps, propsWithTitle)
-					if propsWithTitle.QProperty != nil {
-						propsWithTitle = propsWithTitle.QProperty
-					}
-					title := propsWithTitle.QMetaDef.Title
-					id := propsWithTitle.QInfo.QId
-					qType := propsWithTitle.QInfo.QType
-					viz := propsWithTitle.Visualization
-					filename := buildEntityFilename(folder+"/objects", qType, viz, title, id)
-					os.MkdirAll(filepath.Dir(filename), os.ModePerm)
-					ioutil.WriteFile(filename, marshalOrFail(rawProps), os.ModePerm)
-				}
-			}
-			waitChannel <- true
-		}(index, item)
-	}
-	for range allInfos {
-		<-waitChannel
+	measureArray, dimensionArray, objectArray := collectEntities(ctx, doc, "Fetched entities")
+	for _, object := range objectArray {
+		propsWithTitle := &UnbuildEntityProperies{}
+		json.Unmarshal(object.JSON, propsWithTitle)
+		if propsWithTitle.QProperty != nil {
+			propsWithTitle = propsWithTitle.QProperty
+		}
+		title := propsWithTitle.QMetaDef.Title
+		id := propsWithTitle.QInfo.QId
+		qType := propsWithTitle.QInfo.QType
+		viz := propsWithTitle.Visualization
+		filename := buildEntityFilename(folder+"/objects", qType, viz, title, id)
+		os.MkdirAll(filepath.Dir(filename), os.ModePerm)
+		ioutil.WriteFile(filename, marshalOrFail(object.JSON), os.ModePerm)
 	}
 	writeMeasures(measureArray, folder)
 	writeDimensions(dimensionArray, folder)
 }
 
+// collectEntities fetches the properties of all measures, dimensions and top level objects in the app.
+// Objects with children are fetched as full property trees. The progress is logged with the description, if set.
+func collectEntities(ctx context.Context, doc *enigma.Doc, progress string) (measureArray, dimensionArray, objectArray []JSONWithOrder) {
+	measureArray = make([]JSONWithOrder, 0)
+	var measureArrayLock sync.Mutex
+	dimensionArray = make([]JSONWithOrder, 0)
+	var dimensionArrayLock sync.Mutex
+	objectArray = make([]JSONWithOrder, 0)
+	var objectArrayLock sync.Mutex
+	allInfos, err := doc.GetAllInfos(ctx)
+	if err != nil {
+		log.Fatalln("could not list the entities of the app:", err)
+	}
+	errs := forEach(len(allInfos), progress, func(index int) error {
+		item := allInfos[index]
+		if dimension, err := doc.GetDimension(ctx, item.Id); err != nil {
+			return fmt.Errorf("could not get dimension %s: %s", item.Id, err)
+		} else if dimension.Type != "" {
+			props, err := dimension.GetPropertiesRaw(ctx)
+			if err != nil {
+				return fmt.Errorf("could not get properties of dimension %s: %s", item.Id, err)
+			}
+			dimensionArrayLock.Lock()
+			dimensionArray = append(dimensionArray, JSONWithOrder{props, index})
+			dimensionArrayLock.Unlock()
+			return nil
+		}
+		if measure, err := doc.GetMeasure(ctx, item.Id); err != nil {
+			return fmt.Errorf("could not get measure %s: %s", item.Id, err)
+		} else if measure.Type != "" {
+			props, err := measure.GetPropertiesRaw(ctx)
+			if err != nil {
+				return fmt.Errorf("could not get properties of measure %s: %s", item.Id, err)
+			}
+			measureArrayLock.Lock()
+			measureArray = append(measureArray, JSONWithOrder{props, index})
+			measureArrayLock.Unlock()
+			return nil
+		}
+		object, err := doc.GetObject(ctx, item.Id)
+		if err != nil {
+			return fmt.Errorf("could not get object %s: %s", item.Id, err)
+		} else if object.Type == "" {
+			return nil
+		}
+		parent, err := object.GetParent(ctx)
+		if err != nil {
+			return fmt.Errorf("could not get parent of object %s: %s", item.Id, err)
+		}
+		if parent.Handle != 0 {
+			// Children are part of the property tree of their parent
+			return nil
+		}
+		children, err := object.GetChildInfos(ctx)
+		if err != nil {
+			return fmt.Errorf("could not get children of object %s: %s", item.Id, err)
+		}
+		var rawProps json.RawMessage
+		if len(children) > 0 {
+			rawProps, err = object.GetFullPropertyTreeRaw(ctx)
+		} else {
+			rawProps, err = object.GetPropertiesRaw(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("could not get properties of object %s: %s", item.Id, err)
+		}
+		objectArrayLock.Lock()
+		objectArray = append(objectArray, JSONWithOrder{rawProps, index})
+		objectArrayLock.Unlock()
+		return nil
+	})
+	failOnErrors(errs, "could not fetch all entities of the app")
+	sortJSONArray(objectArray)
+	return
+}
+
 func exportVariables(ctx context.Context, doc *enigma.Doc, folder string) {
+	writeVariables(collectVariables(ctx, doc, "Fetched variables"), folder)
+}
+
+// collectVariables fetches the properties of all variables in the app, logging the progress with the
+// description if set
+func collectVariables(ctx context.Context, doc *enigma.Doc, progress string) []JSONWithOrder {
 	variableArray := make([]JSONWithOrder, 0)
 	var variarbleArraySync sync.Mutex
 	variables := ListVariables(ctx, doc)
-	waitChannel := make(chan interface{}, 10000)
-	defer close(waitChannel)
-	for index, item := range variables {
-		go func(index int, item NamedItem) {
-			if variable, _ := doc.GetVariableByName(ctx, item.Title); variable != nil && variable.Handle != 0 {
-				variarbleArraySync.Lock()
-				props, _ := variable.GetPropertiesRaw(ctx)
-				variableArray = append(variableArray, JSONWithOrder{props, index})
-				variarbleArraySync.Unlock()
-			}
-			waitChannel <- true
-		}(index, item)
-	}
-	for range variables {
-		<-waitChannel
-	}
-	writeVariables(variableArray, folder)
+	errs := forEach(len(variables), progress, func(index int) error {
+		item := variables[index]
+		variable, err := doc.GetVariableByName(ctx, item.Title)
+		if err != nil {
+			return fmt.Errorf("could not get variable %s: %s", item.Title, err)
+		} else if variable.Handle == 0 {
+			return nil
+		}
+		props, err := variable.GetPropertiesRaw(ctx)
+		if err != nil {
+			return fmt.Errorf("could not get properties of variable %s: %s", item.Title, err)
+		}
+		variarbleArraySync.Lock()
+		variableArray = append(variableArray, JSONWithOrder{props, index})
+		variarbleArraySync.Unlock()
+		return nil
+	})
+	failOnErrors(errs, "could not fetch all variables of the app")
+	return variableArray
 }
 
 func exportScript(ctx context.Context, doc *enigma.Doc, folder string) {
-	script, _ := doc.GetScript(ctx)
+	script, err := doc.GetScript(ctx)
+	if err != nil {
+		log.Fatalf("could not retrieve script: %s\n", err)
+	}
 	ioutil.WriteFile(folder+"/script.qvs", []byte(script), os.ModePerm)
 	log.Verboseln("Exported script to " + folder + "/script.qvs")
 }
 
 func exportAppProperties(ctx context.Context, doc *enigma.Doc, folder string) {
-	appProperties, _ := doc.GetAppProperties(ctx)
+	appProperties, err := doc.GetAppProperties(ctx)
+	if err != nil {
+		log.Fatalf("could not retrieve app properties: %s\n", err)
+	}
 	ioutil.WriteFile(folder+"/app-properties.json", marshalOrFail(appProperties), os.ModePerm)
 	log.Verboseln("Exported app properties to " + folder + "/app-properties.json")
 }
 
 func exportConnections(ctx context.Context, doc *enigma.Doc, folder string) {
-	connections, _ := doc.GetConnections(ctx)
+	connections, err := doc.GetConnections(ctx)
+	if err != nil {
+		log.Fatalf("could not retrieve connections: %s\n", err)
+	}
 	connectionsStr := "connections:\n"
 	for _, x := range connections {
 		connectionsStr += "  " + x.Name + ":" + "\n"
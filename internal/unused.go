@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"context"
+	"sort"
+
+	"github.com/qlik-oss/enigma-go"
+)
+
+type (
+	// UnusedItems lists the fields and master items that no object in the app uses, directly or
+	// through master items and variables.
+	UnusedItems struct {
+		Fields     []*UnusedField `json:"fields"`
+		Measures   []NamedItem    `json:"measures"`
+		Dimensions []NamedItem    `json:"dimensions"`
+		Variables  []NamedItem    `json:"variables"`
+		// Estimated number of bytes that could be saved by dropping the unused fields
+		ByteSize int `json:"byteSize"`
+	}
+
+	// UnusedField is a field that no object uses
+	UnusedField struct {
+		Name     string `json:"name"`
+		ByteSize int    `json:"byteSize"`
+	}
+)
+
+// FindUnused cross-references the fields in the data model with all expressions in the app.
+// Key fields and system fields are never reported since they are needed by the data model itself.
+func FindUnused(ctx context.Context, doc *enigma.Doc, data *ModelMetadata) *UnusedItems {
+	fieldNames := []string{}
+	for _, field := range data.Fields {
+		if field.FieldDescription != nil {
+			fieldNames = append(fieldNames, field.Name)
+		}
+	}
+	return findUnused(BuildDependencyGraph(ctx, doc, fieldNames), data.Fields)
+}
+
+func findUnused(graph *DependencyGraph, fields []*FieldModel) *UnusedItems {
+	used := graph.ReachableFrom("object")
+	result := &UnusedItems{
+		Fields:     []*UnusedField{},
+		Measures:   []NamedItem{},
+		Dimensions: []NamedItem{},
+		Variables:  []NamedItem{},
+	}
+	for _, field := range fields {
+		if field.FieldDescription == nil || field.IsSystem || isKey(field) || used["field:"+field.Name] {
+			continue
+		}
+		result.Fields = append(result.Fields, &UnusedField{Name: field.Name, ByteSize: field.ByteSize})
+		result.ByteSize += field.ByteSize
+	}
+	sort.SliceStable(result.Fields, func(i, j int) bool {
+		return result.Fields[i].ByteSize > result.Fields[j].ByteSize
+	})
+
+	keys := make([]string, 0, len(graph.Nodes))
+	for key := range graph.Nodes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		node := graph.Nodes[key]
+		if used[key] {
+			continue
+		}
+		item := NamedItem{ID: node.ID, Title: node.Title}
+		switch node.Type {
+		case "measure":
+			result.Measures = append(result.Measures, item)
+		case "dimension":
+			result.Dimensions = append(result.Dimensions, item)
+		case "variable":
+			result.Variables = append(result.Variables, item)
+		}
+	}
+	return result
+}
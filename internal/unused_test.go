@@ -0,0 +1,36 @@
+package internal
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/qlik-oss/enigma-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindUnused(t *testing.T) {
+	used := newEntityExpressions(&LineageNode{ID: "m1", Type: "measure"},
+		json.RawMessage(`{"qInfo":{"qId":"m1","qType":"measure"},"qMeasure":{"qDef":"Sum(Sales) * $(vRate)"}}`))
+	unused := newEntityExpressions(&LineageNode{ID: "m2", Type: "measure"},
+		json.RawMessage(`{"qInfo":{"qId":"m2","qType":"measure"},"qMeasure":{"qDef":"Sum(Cost)"}}`))
+	rate := newEntityExpressions(&LineageNode{ID: "vRate", Type: "variable"},
+		json.RawMessage(`{"qName":"vRate","qDefinition":"1.25"}`))
+	orphan := newEntityExpressions(&LineageNode{ID: "vOrphan", Type: "variable"},
+		json.RawMessage(`{"qName":"vOrphan","qDefinition":"Sum(Cost)"}`))
+	chart := newEntityExpressions(&LineageNode{ID: "chart", Type: "object"},
+		json.RawMessage(`{"qInfo":{"qId":"chart","qType":"kpi"},"qHyperCubeDef":{"qMeasures":[{"qLibraryId":"m1"}]}}`))
+
+	field := func(name string, byteSize int, tags ...string) *FieldModel {
+		return &FieldModel{FieldDescription: &enigma.FieldDescription{Name: name, ByteSize: byteSize, Tags: tags}}
+	}
+	fields := []*FieldModel{field("Sales", 10), field("Cost", 20), field("Comment", 30), field("Id", 40, "$key")}
+	graph := newDependencyGraph([]string{"Sales", "Cost", "Comment", "Id"}, []*entityExpressions{used, unused, rate, orphan, chart})
+
+	result := findUnused(graph, fields)
+	assert.Equal(t, 2, len(result.Fields))
+	assert.Equal(t, "Comment", result.Fields[0].Name)
+	assert.Equal(t, "Cost", result.Fields[1].Name)
+	assert.Equal(t, 50, result.ByteSize)
+	assert.Equal(t, []NamedItem{{ID: "m2"}}, result.Measures)
+	assert.Equal(t, []NamedItem{{ID: "vOrphan", Title: "vOrphan"}}, result.Variables)
+}
@@ -11,7 +11,11 @@ import (
 
 // GetEngineURL gets QIX engine URL from viper
 func GetEngineURL() *url.URL {
-	engine := viper.GetString("engine")
+	return toEngineURL(viper.GetString("engine"))
+}
+
+// toEngineURL parses the engine parameter and exits if it is missing or invalid
+func toEngineURL(engine string) *url.URL {
 	if engine == "" {
 		log.Fatalln("engine URL not specified")
 	}
@@ -88,8 +92,8 @@ func parseEngineURL(engine string) (*url.URL, error) {
 	return u, nil
 }
 
-func buildWebSocketURL(ttl string) string {
-	u := GetEngineURL()
+func buildWebSocketURL(engine, ttl string) string {
+	u := toEngineURL(engine)
 	// Only modify the URL path if there is no path set
 	if u.Path == "" || u.Path == "/" {
 		u.Path = "/app/engineData/ttl/" + ttl
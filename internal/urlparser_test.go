@@ -115,8 +115,7 @@ func TestGetEngineUrl(t *testing.T) {
 func TestBuildEngineUrl(t *testing.T) {
 	// Wrapper function
 	f := func(s, ttl string) string {
-		viper.Set("engine", s)
-		return buildWebSocketURL(ttl)
+		return buildWebSocketURL(s, ttl)
 	}
 	assert.Equal(t, "ws://engine/app/engineData/ttl/30", f("engine", "30"))
 	assert.Equal(t, "ws://engine:1234/app/engineData/ttl/30", f("engine:1234", "30"))
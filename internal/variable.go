@@ -50,9 +50,15 @@ func ListVariables(ctx context.Context, doc *enigma.Doc) []NamedItem {
 			}`),
 		},
 	}
-	sessionObject, _ := doc.CreateSessionObject(ctx, props)
+	sessionObject, err := doc.CreateSessionObject(ctx, props)
+	if err != nil {
+		log.Fatalf("could not list variables: %s\n", err)
+	}
 	defer doc.DestroySessionObject(ctx, sessionObject.GenericId)
-	layout, _ := sessionObject.GetLayout(ctx)
+	layout, err := sessionObject.GetLayout(ctx)
+	if err != nil {
+		log.Fatalf("could not list variables: %s\n", err)
+	}
 	result := []NamedItem{}
 	for _, item := range layout.VariableList.Items {
 		result = append(result, NamedItem{Title: item.Name, ID: item.Info.Id})
@@ -81,8 +87,11 @@ func SetVariables(ctx context.Context, doc *enigma.Doc, commandLineGlobPattern s
 			if err != nil {
 				log.Fatalf("validation error in file %s: %s\n", path, err)
 			}
-			err = setVariable(ctx, doc, variable.Name, raw)
+			err = retrySet(ctx, "variable", variable.Name, func() error {
+				return setVariable(ctx, doc, variable.Name, raw)
+			})
 			if err != nil {
+				reportEntityError("variables", newEntityError(variable.Name, err))
 				log.Fatalln(err)
 			}
 		}
@@ -1,80 +1,161 @@
 package printer
 
 import (
+	"encoding/csv"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/olekukonko/tablewriter"
+	"github.com/qlik-oss/corectl/internal"
 	"github.com/qlik-oss/corectl/internal/log"
 	"github.com/qlik-oss/enigma-go"
+	"gopkg.in/yaml.v2"
 )
 
-// PrintApps prints a list of apps and some meta to system out.
-func PrintApps(docList []*enigma.DocListEntry, printAsBash bool) {
-	switch mode {
-	case jsonMode:
-		log.PrintAsJSON(filterDocEntries(docList))
-	case bashMode:
-		for _, app := range docList {
+// PrintApps prints a list of apps and the columns of each app to system out. The format is table, csv or yaml,
+// unless in json, bash or quiet mode. All columns are printed in json and yaml.
+func PrintApps(apps []*internal.AppListEntry, columns []string, format string) {
+	switch {
+	case mode == jsonMode:
+		log.PrintAsJSON(filterDocEntries(apps))
+	case format == "yaml":
+		out, err := yaml.Marshal(filterDocEntries(apps))
+		if err != nil {
+			log.Fatalln("could not print apps as yaml:", err)
+		}
+		fmt.Print(string(out))
+	case format == "csv":
+		writer := csv.NewWriter(os.Stdout)
+		writer.Write(columns)
+		for _, app := range apps {
+			writer.Write(appColumns(app, columns))
+		}
+		writer.Flush()
+	case mode == bashMode:
+		for _, app := range apps {
 			PrintToBashComp(app.DocName)
 		}
-	case quietMode:
-		for _, app := range docList {
+	case mode == quietMode:
+		for _, app := range apps {
 			PrintToBashComp(app.DocId)
 		}
 	default:
 		writer := tablewriter.NewWriter(os.Stdout)
 		writer.SetAutoFormatHeaders(false)
-		writer.SetHeader([]string{"Id", "Name", "Last-Reloaded", "ReadOnly", "Title"})
-		for _, doc := range docList {
-			writer.Append([]string{doc.DocId, doc.DocName, doc.LastReloadTime, strconv.FormatBool(doc.ReadOnly), doc.Title})
+		headers := make([]string, len(columns))
+		for i, column := range columns {
+			headers[i] = appColumnHeader(column)
+		}
+		writer.SetHeader(headers)
+		for _, app := range apps {
+			writer.Append(appColumns(app, columns))
 		}
 		writer.Render()
 	}
 }
 
+func appColumns(app *internal.AppListEntry, columns []string) []string {
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		row[i] = app.Column(column)
+	}
+	return row
+}
+
+// appColumnHeader returns the table header of a column, e.g. Last-Reloaded for last-reloaded
+func appColumnHeader(column string) string {
+	switch {
+	case column == "readonly":
+		return "ReadOnly"
+	case strings.HasPrefix(column, "meta."):
+		return column
+	}
+	words := strings.Split(column, "-")
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, "-")
+}
+
+// PrintAppExecResults prints a summary of running a command against many apps
+func PrintAppExecResults(results []*internal.AppExecResult) {
+	switch mode {
+	case jsonMode:
+		log.PrintAsJSON(results)
+	case quietMode:
+		for _, result := range results {
+			if !result.Success {
+				PrintToBashComp(result.AppID)
+			}
+		}
+	default:
+		failed := 0
+		writer := tablewriter.NewWriter(os.Stdout)
+		writer.SetAutoFormatHeaders(false)
+		writer.SetHeader([]string{"Name", "Id", "Result", "Duration"})
+		for _, result := range results {
+			outcome := "ok"
+			if !result.Success {
+				outcome = fmt.Sprintf("failed (exit code %d)", result.ExitCode)
+				failed++
+			}
+			writer.Append([]string{result.AppName, result.AppID, outcome, result.Duration.Round(time.Millisecond).String()})
+		}
+		writer.Render()
+		fmt.Printf("%d of %d apps succeeded\n", len(results)-failed, len(results))
+	}
+}
+
 type filteredDocEntry struct {
 	// Identifier of the app.
-	DocID string `json:"id"`
+	DocID string `json:"id" yaml:"id"`
 	// Name of the app.
-	DocName string `json:"name"`
+	DocName string `json:"name" yaml:"name"`
 	// Title of the app.
-	Title string `json:"title"`
+	Title string `json:"title" yaml:"title"`
+	// Description of the app.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
 	// Last modified time stamp of the app.
-	LastModifiedTime time.Time `json:"lastModifiedTime"`
+	LastModifiedTime time.Time `json:"lastModifiedTime" yaml:"lastModifiedTime"`
 	// Meta data related to the app.
-	LastReloadTime string `json:"lastReloadTime"`
+	LastReloadTime string `json:"lastReloadTime" yaml:"lastReloadTime"`
 	// Size of remote app.
-	FileSize enigma.Float64 `json:"fileSize"`
+	FileSize enigma.Float64 `json:"fileSize" yaml:"fileSize"`
 	// If set to true, the app is read-only.
-	ReadOnly bool `json:"readOnly"`
+	ReadOnly bool `json:"readOnly" yaml:"readOnly"`
+	// If set to true, the app has a thumbnail.
+	HasThumbnail bool `json:"hasThumbnail" yaml:"hasThumbnail"`
+	// If set to true, the app is published.
+	Published bool `json:"published" yaml:"published"`
+	// Owner of the app.
+	Owner string `json:"owner,omitempty" yaml:"owner,omitempty"`
+	// All attributes of qMeta.
+	Meta map[string]interface{} `json:"meta,omitempty" yaml:"meta,omitempty"`
 }
 
-func filterDocEntries(docList []*enigma.DocListEntry) []*filteredDocEntry {
-	result := make([]*filteredDocEntry, len(docList))
-	for i, doc := range docList {
+func filterDocEntries(apps []*internal.AppListEntry) []*filteredDocEntry {
+	result := make([]*filteredDocEntry, len(apps))
+	for i, doc := range apps {
 		result[i] = &filteredDocEntry{
 			DocName:          doc.DocName,
-			LastModifiedTime: serialTimeToString(doc.FileTime),
+			LastModifiedTime: internal.SerialTimeToTime(doc.FileTime),
 			FileSize:         doc.FileSize,
 			DocID:            doc.DocId,
 			LastReloadTime:   doc.LastReloadTime,
 			ReadOnly:         doc.ReadOnly,
 			Title:            doc.Title,
+			Description:      doc.Description(),
+			HasThumbnail:     doc.HasThumbnail(),
+			Published:        doc.Published(),
+			Owner:            doc.Owner(),
+			Meta:             doc.Meta,
 		}
 	}
 	return result
 }
 
-func serialTimeToString(filetime enigma.Float64) time.Time {
-	unix := (filetime - 25569) * 86400
-	timestamp := time.Unix(int64(unix), 0).UTC()
-	return timestamp
-}
-
 // PrintToBashComp handles strings that should be included as options when using auto completion
 func PrintToBashComp(str string) {
 	if strings.Contains(str, " ") {
@@ -0,0 +1,70 @@
+package printer
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/qlik-oss/corectl/internal"
+	"github.com/qlik-oss/corectl/internal/log"
+)
+
+// PrintAppDiff prints the differences between two apps, section by section
+func PrintAppDiff(diff *internal.AppDiff) {
+	if mode == jsonMode {
+		log.PrintAsJSON(diff)
+		return
+	}
+	fmt.Println("*** Script ***")
+	if diff.Script == "" {
+		fmt.Println("No differences.")
+	} else {
+		fmt.Print(diff.Script)
+	}
+	printEntityDiff("Connections", diff.Connections)
+	printEntityDiff("Variables", diff.Variables)
+	printEntityDiff("Master measures", diff.Measures)
+	printEntityDiff("Master dimensions", diff.Dimensions)
+	printEntityDiff("Sheets", diff.Sheets)
+	printEntityDiff("Objects", diff.Objects)
+
+	fmt.Println("\n*** Data model ***")
+	if len(diff.Tables) == 0 {
+		fmt.Println("No differences.")
+		return
+	}
+	writer := tablewriter.NewWriter(os.Stdout)
+	writer.SetAutoFormatHeaders(false)
+	writer.SetHeader([]string{"Table", "Status", "Rows", "Other rows", "Fields"})
+	for _, table := range diff.Tables {
+		fields := []string{}
+		for _, field := range table.AddedFields {
+			fields = append(fields, "+"+field)
+		}
+		for _, field := range table.RemovedFields {
+			fields = append(fields, "-"+field)
+		}
+		writer.Append([]string{table.Name, table.Status, strconv.Itoa(table.Rows), strconv.Itoa(table.OtherRows), strings.Join(fields, ", ")})
+	}
+	writer.Render()
+}
+
+func printEntityDiff(title string, diff *internal.EntityDiff) {
+	fmt.Printf("\n*** %s ***\n", title)
+	if diff.Empty() {
+		fmt.Println("No differences.")
+		return
+	}
+	for _, id := range diff.Added {
+		fmt.Println("+ " + id)
+	}
+	for _, id := range diff.Removed {
+		fmt.Println("- " + id)
+	}
+	for _, changed := range diff.Changed {
+		fmt.Println("~ " + changed.ID)
+		fmt.Print(changed.Diff)
+	}
+}
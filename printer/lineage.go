@@ -0,0 +1,83 @@
+package printer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qlik-oss/corectl/internal"
+	"github.com/qlik-oss/corectl/internal/log"
+)
+
+// PrintLineage prints the entities depending on a field as a tree, in JSON or in Graphviz DOT format.
+func PrintLineage(lineage *internal.Lineage, asDot bool) {
+	switch {
+	case mode == jsonMode:
+		log.PrintAsJSON(lineage)
+	case asDot:
+		printLineageDot(lineage)
+	case len(lineage.Nodes) <= 1:
+		fmt.Printf("Nothing in the app depends on field '%s'.\n", lineage.Field)
+	default:
+		nodes := lineageNodesByKey(lineage)
+		printLineageTree(lineage, nodes, "field:"+lineage.Field, 0, map[string]bool{})
+	}
+}
+
+func lineageNodesByKey(lineage *internal.Lineage) map[string]*internal.LineageNode {
+	nodes := map[string]*internal.LineageNode{}
+	for _, node := range lineage.Nodes {
+		nodes[node.Key()] = node
+	}
+	return nodes
+}
+
+func printLineageTree(lineage *internal.Lineage, nodes map[string]*internal.LineageNode, key string, depth int, visited map[string]bool) {
+	node := nodes[key]
+	text := strings.Repeat("  ", depth) + node.Type + " " + node.ID
+	if node.Title != "" && node.Title != node.ID {
+		text += fmt.Sprintf(" (%s)", node.Title)
+	}
+	if visited[key] {
+		fmt.Println(text + " ...")
+		return
+	}
+	fmt.Println(text)
+	visited[key] = true
+	for _, edge := range lineage.Edges {
+		if edge.To == key {
+			printLineageTree(lineage, nodes, edge.From, depth+1, visited)
+		}
+	}
+}
+
+func printLineageDot(lineage *internal.Lineage) {
+	shapes := map[string]string{
+		"field":     "cylinder",
+		"variable":  "diamond",
+		"measure":   "ellipse",
+		"dimension": "ellipse",
+		"object":    "box",
+	}
+	fmt.Println("digraph lineage {")
+	fmt.Println("  rankdir=LR;")
+	for _, node := range lineage.Nodes {
+		lines := []string{node.Type, node.ID}
+		if node.Title != "" && node.Title != node.ID {
+			lines = append(lines, node.Title)
+		}
+		fmt.Printf("  %s [label=%s shape=%s];\n", dotQuote(node.Key()), dotQuote(lines...), shapes[node.Type])
+	}
+	for _, edge := range lineage.Edges {
+		fmt.Printf("  %s -> %s;\n", dotQuote(edge.From), dotQuote(edge.To))
+	}
+	fmt.Println("}")
+}
+
+// dotQuote escapes and quotes the lines as a DOT string, separating them with line breaks
+func dotQuote(lines ...string) string {
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = strings.Replace(strings.Replace(line, `\`, `\\`, -1), `"`, `\"`, -1)
+	}
+	return `"` + strings.Join(escaped, `\n`) + `"`
+}
@@ -0,0 +1,132 @@
+package printer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/qlik-oss/corectl/internal"
+	"github.com/qlik-oss/corectl/internal/log"
+)
+
+// PrintLintFindings prints the findings of a linter along with a summary per severity.
+func PrintLintFindings(findings []*internal.LintFinding) {
+	if mode == jsonMode {
+		log.PrintAsJSON(findings)
+		return
+	}
+	if len(findings) == 0 {
+		fmt.Println("No problems found.")
+		return
+	}
+	writer := tablewriter.NewWriter(os.Stdout)
+	writer.SetAutoFormatHeaders(false)
+	writer.SetHeader([]string{"Severity", "Rule", "Location", "Message"})
+	counts := map[string]int{}
+	for _, finding := range findings {
+		writer.Append([]string{finding.Severity, finding.Rule, lintLocation(finding), finding.Message})
+		counts[finding.Severity]++
+	}
+	writer.Render()
+	fmt.Printf("%d error(s), %d warning(s), %d info\n", counts["error"], counts["warning"], counts["info"])
+}
+
+func lintLocation(finding *internal.LintFinding) string {
+	switch {
+	case finding.File != "" && finding.Pointer != "":
+		return finding.File + "#" + finding.Pointer
+	case finding.File != "" && finding.Line > 0:
+		return fmt.Sprintf("%s:%d", finding.File, finding.Line)
+	case finding.File != "":
+		return finding.File
+	case finding.Table != "" && finding.Field != "":
+		return finding.Table + "." + finding.Field
+	case finding.Table != "":
+		return finding.Table
+	}
+	return finding.Field
+}
+
+// sarifLevels maps the lint severities to SARIF result levels
+var sarifLevels = map[string]string{
+	"error":   "error",
+	"warning": "warning",
+	"info":    "note",
+}
+
+// PrintLintFindingsAsSarif prints the findings as a SARIF 2.1.0 log, suitable for code scanning tools.
+func PrintLintFindingsAsSarif(findings []*internal.LintFinding) {
+	type (
+		sarifRule struct {
+			ID string `json:"id"`
+		}
+		sarifMessage struct {
+			Text string `json:"text"`
+		}
+		sarifArtifact struct {
+			URI string `json:"uri"`
+		}
+		sarifRegion struct {
+			StartLine int `json:"startLine"`
+		}
+		sarifPhysicalLocation struct {
+			ArtifactLocation sarifArtifact `json:"artifactLocation"`
+			Region           *sarifRegion  `json:"region,omitempty"`
+		}
+		sarifLogicalLocation struct {
+			FullyQualifiedName string `json:"fullyQualifiedName"`
+		}
+		sarifLocation struct {
+			PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+			LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+		}
+		sarifResult struct {
+			RuleID    string          `json:"ruleId"`
+			Level     string          `json:"level"`
+			Message   sarifMessage    `json:"message"`
+			Locations []sarifLocation `json:"locations,omitempty"`
+		}
+	)
+	rules := []sarifRule{}
+	seen := map[string]bool{}
+	results := []sarifResult{}
+	for _, finding := range findings {
+		if !seen[finding.Rule] {
+			seen[finding.Rule] = true
+			rules = append(rules, sarifRule{ID: finding.Rule})
+		}
+		result := sarifResult{
+			RuleID:  finding.Rule,
+			Level:   sarifLevels[finding.Severity],
+			Message: sarifMessage{Text: finding.Message},
+		}
+		if finding.File != "" {
+			location := sarifPhysicalLocation{ArtifactLocation: sarifArtifact{URI: filepath.ToSlash(finding.File)}}
+			if finding.Line > 0 {
+				location.Region = &sarifRegion{StartLine: finding.Line}
+			}
+			result.Locations = []sarifLocation{{PhysicalLocation: location}}
+			if finding.Pointer != "" {
+				// The JSON pointer of the problem within the file
+				result.Locations[0].LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: finding.Pointer}}
+			}
+		}
+		results = append(results, result)
+	}
+	log.PrintAsJSON(map[string]interface{}{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []interface{}{
+			map[string]interface{}{
+				"tool": map[string]interface{}{
+					"driver": map[string]interface{}{
+						"name":  "corectl",
+						"rules": rules,
+					},
+				},
+				"results": results,
+			},
+		},
+	})
+}
@@ -0,0 +1,46 @@
+package printer
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/qlik-oss/corectl/internal"
+	"github.com/qlik-oss/corectl/internal/log"
+)
+
+// Number of steps shown in the summary of a reload profile
+const slowestStepsShown = 10
+
+// PrintReloadProfile prints the slowest steps of the reload along with their share of the total time
+func PrintReloadProfile(profile *internal.ReloadProfile) {
+	if mode == jsonMode {
+		log.PrintAsJSON(profile)
+		return
+	}
+	if mode == quietMode {
+		return
+	}
+	fmt.Printf("Reload took %s, slowest steps:\n", profile.Duration.Round(time.Millisecond))
+	writer := tablewriter.NewWriter(os.Stdout)
+	writer.SetAutoFormatHeaders(false)
+	writer.SetHeader([]string{"Step", "Rows", "Duration", "Share"})
+	writer.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_RIGHT})
+	for i, step := range profile.Slowest() {
+		if i == slowestStepsShown {
+			break
+		}
+		rows := ""
+		if step.Table != "" {
+			rows = strconv.Itoa(step.Rows)
+		}
+		share := 0.0
+		if profile.Duration > 0 {
+			share = float64(step.Duration) / float64(profile.Duration) * 100
+		}
+		writer.Append([]string{step.Name, rows, step.Duration.Round(time.Millisecond).String(), fmt.Sprintf("%.1f%%", share)})
+	}
+	writer.Render()
+}
@@ -0,0 +1,103 @@
+package printer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/qlik-oss/corectl/internal"
+)
+
+// WriteTAP writes the test cases in the Test Anything Protocol format, failures are described in YAML blocks
+func WriteTAP(w io.Writer, cases []*internal.TestCase) {
+	fmt.Fprintln(w, "TAP version 13")
+	fmt.Fprintf(w, "1..%d\n", len(cases))
+	for i, testCase := range cases {
+		name := testCase.Name
+		if testCase.Group != "" {
+			name = testCase.Group + ": " + name
+		}
+		if testCase.Passed() {
+			fmt.Fprintf(w, "ok %d - %s\n", i+1, name)
+			continue
+		}
+		fmt.Fprintf(w, "not ok %d - %s\n", i+1, name)
+		fmt.Fprintln(w, "  ---")
+		fmt.Fprintf(w, "  message: %q\n", testCase.Failure)
+		fmt.Fprintf(w, "  duration_ms: %d\n", testCase.Duration.Milliseconds())
+		fmt.Fprintln(w, "  ...")
+	}
+}
+
+// WriteJUnit writes the test cases as a JUnit XML report with one test suite per group
+func WriteJUnit(w io.Writer, suiteName string, cases []*internal.TestCase) {
+	type (
+		junitFailure struct {
+			Message string `xml:"message,attr"`
+			Text    string `xml:",chardata"`
+		}
+		junitTestCase struct {
+			Name      string        `xml:"name,attr"`
+			Classname string        `xml:"classname,attr"`
+			Time      string        `xml:"time,attr"`
+			Failure   *junitFailure `xml:"failure,omitempty"`
+		}
+		junitTestSuite struct {
+			Name      string           `xml:"name,attr"`
+			Tests     int              `xml:"tests,attr"`
+			Failures  int              `xml:"failures,attr"`
+			Time      string           `xml:"time,attr"`
+			TestCases []*junitTestCase `xml:"testcase"`
+		}
+		junitTestSuites struct {
+			XMLName    xml.Name          `xml:"testsuites"`
+			Name       string            `xml:"name,attr"`
+			Tests      int               `xml:"tests,attr"`
+			Failures   int               `xml:"failures,attr"`
+			Time       string            `xml:"time,attr"`
+			TestSuites []*junitTestSuite `xml:"testsuite"`
+		}
+	)
+	seconds := func(d float64) string {
+		return fmt.Sprintf("%.3f", d)
+	}
+	report := &junitTestSuites{Name: suiteName}
+	suites := map[string]*junitTestSuite{}
+	totalTime := 0.0
+	suiteTimes := map[*junitTestSuite]float64{}
+	for _, testCase := range cases {
+		group := testCase.Group
+		if group == "" {
+			group = suiteName
+		}
+		suite := suites[group]
+		if suite == nil {
+			suite = &junitTestSuite{Name: group}
+			suites[group] = suite
+			report.TestSuites = append(report.TestSuites, suite)
+		}
+		duration := testCase.Duration.Seconds()
+		junitCase := &junitTestCase{Name: testCase.Name, Classname: strings.TrimSuffix(group, ".yml"), Time: seconds(duration)}
+		if !testCase.Passed() {
+			message := strings.SplitN(testCase.Failure, "\n", 2)[0]
+			junitCase.Failure = &junitFailure{Message: message, Text: testCase.Failure}
+			suite.Failures++
+			report.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, junitCase)
+		suite.Tests++
+		report.Tests++
+		suiteTimes[suite] += duration
+		totalTime += duration
+	}
+	for suite, time := range suiteTimes {
+		suite.Time = seconds(time)
+	}
+	report.Time = seconds(totalTime)
+	fmt.Fprint(w, xml.Header)
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	encoder.Encode(report)
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,58 @@
+package printer
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/qlik-oss/corectl/internal"
+	"github.com/qlik-oss/corectl/internal/log"
+)
+
+// PrintUnused prints the unused fields, master items and variables along with the memory that could be saved.
+func PrintUnused(unused *internal.UnusedItems) {
+	switch mode {
+	case jsonMode:
+		log.PrintAsJSON(unused)
+	case bashMode:
+		fallthrough
+	case quietMode:
+		for _, field := range unused.Fields {
+			PrintToBashComp(field.Name)
+		}
+	default:
+		fmt.Println("*** Unused fields ***")
+		if len(unused.Fields) == 0 {
+			fmt.Println("No unused fields found.")
+		} else {
+			writer := tablewriter.NewWriter(os.Stdout)
+			writer.SetAutoFormatHeaders(false)
+			writer.SetHeader([]string{"Field", "RAM"})
+			for _, field := range unused.Fields {
+				writer.Append([]string{field.Name, internal.FormatBytes(field.ByteSize)})
+			}
+			writer.SetFooter([]string{"Estimated savings", internal.FormatBytes(unused.ByteSize)})
+			writer.Render()
+		}
+		fmt.Println("\n*** Unused master measures ***")
+		printUnusedItems(unused.Measures, "master measures")
+		fmt.Println("\n*** Unused master dimensions ***")
+		printUnusedItems(unused.Dimensions, "master dimensions")
+		fmt.Println("\n*** Orphaned variables ***")
+		printUnusedItems(unused.Variables, "orphaned variables")
+	}
+}
+
+func printUnusedItems(items []internal.NamedItem, name string) {
+	if len(items) == 0 {
+		fmt.Printf("No %s found.\n", name)
+		return
+	}
+	writer := tablewriter.NewWriter(os.Stdout)
+	writer.SetAutoFormatHeaders(false)
+	writer.SetHeader([]string{"ID", "Title"})
+	for _, item := range items {
+		writer.Append([]string{item.ID, item.Title})
+	}
+	writer.Render()
+}
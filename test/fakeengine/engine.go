@@ -0,0 +1,318 @@
+// Package fakeengine is an in-process fake of the Qlik Associative Engine for tests. It speaks enough of the QIX
+// JSON-RPC protocol to open and create apps, manage entities and connections, set the script and reload the app
+// with canned progress and a canned data model. Methods can be overridden, or added, with Handle.
+package fakeengine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+type (
+	// Engine is a fake engine serving websocket connections on a local port
+	Engine struct {
+		// URL is the websocket URL of the engine once it is started
+		URL string
+		// Version is the version reported by EngineVersion
+		Version string
+		// Apps are the apps in the engine by id
+		Apps map[string]*App
+
+		handlers map[string]Handler
+		nextID   int
+		// sessions are the sessions kept alive after disconnecting, by X-Qlik-Session
+		sessions map[string]*Session
+		conns    map[*websocket.Conn]bool
+		mutex    sync.Mutex
+		listener net.Listener
+		server   *http.Server
+	}
+
+	// App is an app in the fake engine
+	App struct {
+		ID       string
+		Title    string
+		Script   string
+		Entities map[string]*Entity
+		// Connections are the data connections by id
+		Connections map[string]map[string]interface{}
+		Properties  map[string]interface{}
+		// Meta is the qMeta of the app in the doc list
+		Meta map[string]interface{}
+		// FileSize is the size of the app in the doc list
+		FileSize float64
+		// Tables is the data model returned by GetTablesAndKeys
+		Tables []*Table
+		// ReloadTables becomes the data model when the app is reloaded
+		ReloadTables []*Table
+		// ReloadProgress are the lines of progress reported when the app is reloaded
+		ReloadProgress []string
+		// ReloadFails makes reloads unsuccessful
+		ReloadFails bool
+		// Saved counts the number of times the app has been saved
+		Saved    int
+		Reloaded int
+	}
+
+	// Entity is a generic object, dimension, measure, variable or bookmark
+	Entity struct {
+		// Kind is one of object, dimension, measure, variable and bookmark
+		Kind       string
+		Properties map[string]interface{}
+		Children   []interface{}
+	}
+
+	// Table is a table in the data model of an app
+	Table struct {
+		Name   string
+		Rows   int
+		Fields []string
+	}
+
+	// Handler implements a method. The handle identifies the object the method is called on, -1 for Global. The
+	// result is sent as the result of the response, or the error as an error response. Returning ErrNotHandled
+	// falls back to the implementation of the fake engine.
+	Handler func(session *Session, handle int, params []json.RawMessage) (interface{}, error)
+
+	// Session is the state of one websocket connection. Connections with an X-Qlik-Session header and a ttl in
+	// the url, e.g. ws://host/ttl/60, re-attach to the session if they reconnect.
+	Session struct {
+		// Header is the header of the request that opened the connection
+		Header  http.Header
+		engine  *Engine
+		conn    *websocket.Conn
+		app     *App
+		handles map[int]*handleTarget
+		// sessionObjects are the session objects of the connection by id
+		sessionObjects map[string]*Entity
+		nextHandle     int
+		progress       []string
+	}
+
+	handleTarget struct {
+		qType  string
+		id     string
+		entity *Entity
+	}
+
+	// Error is a JSON-RPC error returned by a handler
+	Error struct {
+		Code      int    `json:"code"`
+		Parameter string `json:"parameter,omitempty"`
+		Message   string `json:"message"`
+	}
+
+	request struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Handle int               `json:"handle"`
+		Params []json.RawMessage `json:"params"`
+	}
+)
+
+// Matches urls with a session ttl, e.g. /app/engineData/ttl/60
+var ttlRegexp = regexp.MustCompile(`/ttl/\d+$`)
+
+// ErrNotHandled is returned by a handler to use the implementation of the fake engine
+var ErrNotHandled = errors.New("not handled")
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+// New creates a fake engine without apps
+func New() *Engine {
+	return &Engine{
+		Version:  "12.0.0",
+		Apps:     map[string]*App{},
+		handlers: map[string]Handler{},
+		sessions: map[string]*Session{},
+		conns:    map[*websocket.Conn]bool{},
+	}
+}
+
+// Start starts serving on a free local port
+func (e *Engine) Start() error {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return err
+	}
+	e.listener = listener
+	e.URL = "ws://" + listener.Addr().String()
+	e.server = &http.Server{Handler: http.HandlerFunc(e.serveWebsocket)}
+	go e.server.Serve(listener)
+	return nil
+}
+
+// Address returns the host and port of the engine, e.g. to use as --engine
+func (e *Engine) Address() string {
+	return e.listener.Addr().String()
+}
+
+// Close stops the engine
+func (e *Engine) Close() error {
+	if e.server == nil {
+		return nil
+	}
+	return e.server.Close()
+}
+
+// AddApp adds an empty app with the id as title
+func (e *Engine) AddApp(id string) *App {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.addApp(id)
+}
+
+func (e *Engine) addApp(id string) *App {
+	app := &App{
+		ID:          id,
+		Title:       id,
+		Entities:    map[string]*Entity{},
+		Connections: map[string]map[string]interface{}{},
+		Properties:  map[string]interface{}{"qTitle": id},
+	}
+	e.Apps[id] = app
+	return app
+}
+
+// Handle overrides the implementation of a method, or adds a method that is not implemented
+func (e *Engine) Handle(method string, handler Handler) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.handlers[method] = handler
+}
+
+// DropConnections closes all websocket connections, like an engine restart or a network failure would, but
+// keeps the sessions that can be re-attached
+func (e *Engine) DropConnections() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	for conn := range e.conns {
+		conn.Close()
+	}
+}
+
+// App returns the app opened in the session, if any
+func (s *Session) App() *App {
+	return s.app
+}
+
+// Disconnect closes the websocket connection of the session, e.g. in a handler to drop the connection in
+// the middle of a request
+func (s *Session) Disconnect() {
+	s.conn.Close()
+}
+
+// session returns the session to re-attach to, if any, or a new session along with the session state
+func (e *Engine) session(r *http.Request, conn *websocket.Conn) (*Session, string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.conns[conn] = true
+	id := r.Header.Get("X-Qlik-Session")
+	keepAlive := id != "" && ttlRegexp.MatchString(r.URL.Path) && !strings.HasSuffix(r.URL.Path, "/ttl/0")
+	if session := e.sessions[id]; session != nil && keepAlive {
+		session.conn = conn
+		session.Header = r.Header
+		return session, "SESSION_ATTACHED"
+	}
+	session := &Session{Header: r.Header, engine: e, conn: conn, handles: map[int]*handleTarget{-1: {qType: "Global"}}, sessionObjects: map[string]*Entity{}, nextHandle: 1}
+	if keepAlive {
+		e.sessions[id] = session
+	}
+	return session, "SESSION_CREATED"
+}
+
+func (e *Engine) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer func() {
+		e.mutex.Lock()
+		delete(e.conns, conn)
+		e.mutex.Unlock()
+		conn.Close()
+	}()
+	session, state := e.session(r, conn)
+	conn.WriteJSON(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "OnConnected",
+		"params":  map[string]string{"qSessionState": state},
+	})
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		req := &request{}
+		if err := json.Unmarshal(message, req); err != nil {
+			continue
+		}
+		response := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		result, err := e.call(session, req)
+		if err != nil {
+			rpcErr, ok := err.(*Error)
+			if !ok {
+				rpcErr = &Error{Code: -32000, Message: err.Error()}
+			}
+			response["error"] = rpcErr
+		} else {
+			response["result"] = result
+		}
+		conn.WriteJSON(response)
+	}
+}
+
+func (e *Engine) call(session *Session, req *request) (interface{}, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if handler := e.handlers[req.Method]; handler != nil {
+		if result, err := handler(session, req.Handle, req.Params); err != ErrNotHandled {
+			return result, err
+		}
+	}
+	target := session.handles[req.Handle]
+	if target == nil {
+		return nil, &Error{Code: -32602, Message: fmt.Sprintf("invalid handle %d", req.Handle)}
+	}
+	var handler func(*Session, *handleTarget, []json.RawMessage) (interface{}, error)
+	switch target.qType {
+	case "Global":
+		handler = globalMethods[req.Method]
+	case "Doc":
+		handler = docMethods[req.Method]
+	default:
+		handler = entityMethods[req.Method]
+	}
+	if handler == nil {
+		return nil, &Error{Code: -32601, Message: fmt.Sprintf("method %s not supported by the fake engine on %s", req.Method, target.qType)}
+	}
+	return handler(session, target, req.Params)
+}
+
+// newHandle registers an object in the session and returns the reference to it
+func (s *Session) newHandle(target *handleTarget) map[string]interface{} {
+	handle := s.nextHandle
+	s.nextHandle++
+	s.handles[handle] = target
+	genericType := target.qType
+	if target.entity != nil {
+		if info, ok := target.entity.Properties["qInfo"].(map[string]interface{}); ok {
+			genericType, _ = info["qType"].(string)
+		}
+	}
+	return map[string]interface{}{"qType": target.qType, "qHandle": handle, "qGenericType": genericType, "qGenericId": target.id}
+}
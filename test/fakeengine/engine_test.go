@@ -0,0 +1,124 @@
+package fakeengine
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/qlik-oss/enigma-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func dial(t *testing.T, engine *Engine) *enigma.Global {
+	t.Helper()
+	global, err := enigma.Dialer{}.Dial(context.Background(), engine.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return global
+}
+
+func TestAppsAndScript(t *testing.T) {
+	ctx := context.Background()
+	engine := New()
+	assert.Nil(t, engine.Start())
+	defer engine.Close()
+	global := dial(t, engine)
+	defer global.DisconnectFromServer()
+
+	success, id, err := global.CreateApp(ctx, "my-app.qvf", "")
+	assert.Nil(t, err)
+	assert.True(t, success)
+	_, err = global.OpenDoc(ctx, "missing.qvf", "", "", "", false)
+	assert.NotNil(t, err)
+
+	doc, err := global.OpenDoc(ctx, id, "", "", "", false)
+	assert.Nil(t, err)
+	assert.Nil(t, doc.SetScript(ctx, "Load 1 as a AutoGenerate 1;"))
+	script, err := doc.GetScript(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "Load 1 as a AutoGenerate 1;", script)
+
+	app := engine.Apps[id]
+	app.ReloadProgress = []string{"Characters << chars 10 Lines fetched", "Numbers << nums 20 Lines fetched"}
+	app.ReloadTables = []*Table{{Name: "Characters", Rows: 10, Fields: []string{"Alpha", "Num"}}, {Name: "Numbers", Rows: 20, Fields: []string{"Num"}}}
+	ok, err := doc.DoReload(ctx, 0, false, false)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	progress, err := global.GetProgress(ctx, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "Characters << chars 10 Lines fetched\nNumbers << nums 20 Lines fetched", progress.PersistentProgress)
+
+	tables, keys, err := doc.GetTablesAndKeys(ctx, &enigma.Size{}, &enigma.Size{}, 0, false, false, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(tables))
+	assert.Equal(t, 10, tables[0].NoOfRows)
+	assert.Equal(t, "ANY_KEY", tables[0].Fields[1].KeyType)
+	assert.Equal(t, []string{"Num"}, keys[0].KeyFields)
+
+	assert.Nil(t, doc.DoSave(ctx, ""))
+	assert.Equal(t, 1, app.Saved)
+}
+
+func TestEntities(t *testing.T) {
+	ctx := context.Background()
+	engine := New()
+	assert.Nil(t, engine.Start())
+	defer engine.Close()
+	engine.AddApp("app")
+	global := dial(t, engine)
+	defer global.DisconnectFromServer()
+	doc, err := global.OpenDoc(ctx, "app", "", "", "", false)
+	assert.Nil(t, err)
+
+	missing, err := doc.GetMeasure(ctx, "m1")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, missing.Handle)
+
+	_, err = doc.CreateMeasureRaw(ctx, json.RawMessage(`{"qInfo":{"qId":"m1","qType":"measure"},"qMeasure":{"qDef":"Sum(a)"},"qMetaDef":{"title":"Total"}}`))
+	assert.Nil(t, err)
+	measure, err := doc.GetMeasure(ctx, "m1")
+	assert.Nil(t, err)
+	assert.NotEqual(t, 0, measure.Handle)
+	assert.Nil(t, measure.SetPropertiesRaw(ctx, json.RawMessage(`{"qInfo":{"qId":"m1","qType":"measure"},"qMeasure":{"qDef":"Sum(b)"},"qMetaDef":{"title":"Total"}}`)))
+	props, err := measure.GetProperties(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "Sum(b)", props.Measure.Def)
+
+	list, err := doc.CreateSessionObject(ctx, &enigma.GenericObjectProperties{
+		Info:           &enigma.NxInfo{Type: "list"},
+		MeasureListDef: &enigma.MeasureListDef{Type: "measure", Data: json.RawMessage(`{"title":"/qMetaDef/title"}`)},
+	})
+	assert.Nil(t, err)
+	layout, err := list.GetLayout(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(layout.MeasureList.Items))
+	assert.Equal(t, "m1", layout.MeasureList.Items[0].Info.Id)
+	assert.JSONEq(t, `{"title":"Total"}`, string(layout.MeasureList.Items[0].Data))
+
+	infos, err := doc.GetAllInfos(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(infos))
+	destroyed, err := doc.DestroyMeasure(ctx, "m1")
+	assert.Nil(t, err)
+	assert.True(t, destroyed)
+	assert.Empty(t, engine.Apps["app"].Entities)
+}
+
+func TestHandle(t *testing.T) {
+	ctx := context.Background()
+	engine := New()
+	assert.Nil(t, engine.Start())
+	defer engine.Close()
+	engine.Handle("EngineVersion", func(session *Session, handle int, params []json.RawMessage) (interface{}, error) {
+		return nil, &Error{Code: 5, Message: "Access denied"}
+	})
+	global := dial(t, engine)
+	defer global.DisconnectFromServer()
+
+	_, err := global.EngineVersion(ctx)
+	assert.NotNil(t, err)
+	assert.Equal(t, 5, err.(enigma.Error).Code())
+	_, err = global.GetUniqueID(ctx)
+	assert.Equal(t, -32601, err.(enigma.Error).Code())
+}
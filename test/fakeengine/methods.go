@@ -0,0 +1,643 @@
+package fakeengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type method func(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error)
+
+var globalMethods = map[string]method{
+	"OpenDoc":              openDoc,
+	"CreateApp":            createApp,
+	"DeleteApp":            deleteApp,
+	"GetDocList":           getDocList,
+	"GetActiveDoc":         getActiveDoc,
+	"GetProgress":          getProgress,
+	"EngineVersion":        engineVersion,
+	"IsDesktopMode":        constant("qReturn", false),
+	"GetInteract":          constant("qReturn", false),
+	"InteractDone":         constant("", nil),
+	"CancelReload":         constant("", nil),
+	"CancelRequest":        constant("", nil),
+	"AbortAll":             constant("", nil),
+	"GetAuthenticatedUser": constant("qReturn", "UserDirectory=Internal; UserId=fake"),
+}
+
+var docMethods = map[string]method{
+	"GetAllInfos":           getAllInfos,
+	"GetScript":             getScript,
+	"SetScript":             setScript,
+	"DoReload":              doReload,
+	"DoSave":                doSave,
+	"SaveObjects":           doSave,
+	"GetTablesAndKeys":      getTablesAndKeys,
+	"GetAppProperties":      getAppProperties,
+	"SetAppProperties":      setAppProperties,
+	"GetAppLayout":          getAppLayout,
+	"GetConnections":        getConnections,
+	"CreateConnection":      createConnection,
+	"ModifyConnection":      modifyConnection,
+	"DeleteConnection":      deleteConnection,
+	"CreateObject":          createEntity("object", "GenericObject", false),
+	"CreateSessionObject":   createEntity("object", "GenericObject", true),
+	"GetObject":             getEntity("object", "GenericObject"),
+	"DestroyObject":         destroyEntity("object"),
+	"DestroySessionObject":  destroySessionObject,
+	"CreateDimension":       createEntity("dimension", "GenericDimension", false),
+	"GetDimension":          getEntity("dimension", "GenericDimension"),
+	"DestroyDimension":      destroyEntity("dimension"),
+	"CreateMeasure":         createEntity("measure", "GenericMeasure", false),
+	"GetMeasure":            getEntity("measure", "GenericMeasure"),
+	"DestroyMeasure":        destroyEntity("measure"),
+	"CreateBookmark":        createEntity("bookmark", "GenericBookmark", false),
+	"GetBookmark":           getEntity("bookmark", "GenericBookmark"),
+	"DestroyBookmark":       destroyEntity("bookmark"),
+	"CreateVariableEx":      createEntity("variable", "GenericVariable", false),
+	"GetVariableById":       getEntity("variable", "GenericVariable"),
+	"GetVariableByName":     getVariableByName,
+	"DestroyVariableById":   destroyEntity("variable"),
+	"DestroyVariableByName": destroyVariableByName,
+	"SetFetchLimit":         constant("", nil),
+	"SetScriptBreakpoints":  constant("", nil),
+	"ClearAll":              constant("", nil),
+	"CheckExpression":       checkExpression,
+}
+
+var entityMethods = map[string]method{
+	"GetProperties":          getProperties,
+	"GetEffectiveProperties": getProperties,
+	"SetProperties":          setProperties,
+	"GetLayout":              getLayout,
+	"GetInfo":                getInfo,
+	"GetFullPropertyTree":    getFullPropertyTree,
+	"SetFullPropertyTree":    setFullPropertyTree,
+	"GetParent":              constant("qReturn", map[string]interface{}{}),
+	"GetChildInfos":          getChildInfos,
+}
+
+// constant returns a method with a fixed result, named key, or an empty result if key is empty
+func constant(key string, value interface{}) method {
+	return func(*Session, *handleTarget, []json.RawMessage) (interface{}, error) {
+		if key == "" {
+			return map[string]interface{}{}, nil
+		}
+		return map[string]interface{}{key: value}, nil
+	}
+}
+
+// param decodes the parameter at index i, leaving value untouched if it is missing
+func param(params []json.RawMessage, i int, value interface{}) error {
+	if i >= len(params) || len(params[i]) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params[i], value); err != nil {
+		return &Error{Code: -32602, Message: fmt.Sprintf("invalid parameter %d: %s", i, err)}
+	}
+	return nil
+}
+
+func openDoc(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	var name string
+	if err := param(params, 0, &name); err != nil {
+		return nil, err
+	}
+	app := session.engine.Apps[name]
+	if app == nil {
+		for _, candidate := range session.engine.Apps {
+			if candidate.Title == name {
+				app = candidate
+			}
+		}
+	}
+	if app == nil {
+		return nil, &Error{Code: 1002, Parameter: name, Message: "App not found"}
+	}
+	if session.app != nil {
+		return nil, &Error{Code: 1009, Message: "App already open"}
+	}
+	session.app = app
+	return map[string]interface{}{"qReturn": session.newHandle(&handleTarget{qType: "Doc", id: app.ID})}, nil
+}
+
+func createApp(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	var name string
+	if err := param(params, 0, &name); err != nil {
+		return nil, err
+	}
+	if session.engine.Apps[name] != nil {
+		return map[string]interface{}{"qSuccess": false, "qAppId": ""}, nil
+	}
+	session.engine.addApp(name)
+	return map[string]interface{}{"qSuccess": true, "qAppId": name}, nil
+}
+
+func deleteApp(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	var id string
+	if err := param(params, 0, &id); err != nil {
+		return nil, err
+	}
+	if session.engine.Apps[id] == nil {
+		return nil, &Error{Code: 1002, Parameter: id, Message: "App not found"}
+	}
+	delete(session.engine.Apps, id)
+	return map[string]interface{}{"qSuccess": true}, nil
+}
+
+func getDocList(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	docs := []map[string]interface{}{}
+	for _, id := range sortedIDs(session.engine.Apps) {
+		app := session.engine.Apps[id]
+		meta := app.Meta
+		if meta == nil {
+			meta = map[string]interface{}{}
+		}
+		docs = append(docs, map[string]interface{}{
+			"qDocName":  app.Title,
+			"qDocId":    app.ID,
+			"qTitle":    app.Title,
+			"qFileSize": app.FileSize,
+			"qMeta":     meta,
+		})
+	}
+	return map[string]interface{}{"qDocList": docs}, nil
+}
+
+func getActiveDoc(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	if session.app == nil {
+		return nil, &Error{Code: 1007, Message: "App invalid"}
+	}
+	for handle, target := range session.handles {
+		if target.qType == "Doc" {
+			return map[string]interface{}{"qReturn": map[string]interface{}{"qType": "Doc", "qHandle": handle, "qGenericId": target.id}}, nil
+		}
+	}
+	return nil, &Error{Code: 1007, Message: "App invalid"}
+}
+
+// getProgress reports the canned progress of the last reload as persistent progress, all at once
+func getProgress(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	progress := map[string]interface{}{"qFinished": true}
+	if len(session.progress) > 0 {
+		progress["qPersistentProgress"] = strings.Join(session.progress, "\n")
+		session.progress = nil
+	}
+	return map[string]interface{}{"qProgressData": progress}, nil
+}
+
+func engineVersion(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	return map[string]interface{}{"qVersion": map[string]string{"qComponentVersion": session.engine.Version}}, nil
+}
+
+func getAllInfos(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	infos := []interface{}{}
+	app := session.app
+	for _, id := range sortedIDs(app.Entities) {
+		infos = append(infos, app.Entities[id].Properties["qInfo"])
+	}
+	return map[string]interface{}{"qInfos": infos}, nil
+}
+
+func getScript(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	return map[string]interface{}{"qScript": session.app.Script}, nil
+}
+
+func setScript(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	if err := param(params, 0, &session.app.Script); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{}, nil
+}
+
+// doReload completes the reload immediately, the progress is reported by the next GetProgress call
+func doReload(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	app := session.app
+	app.Reloaded++
+	session.progress = append(session.progress, app.ReloadProgress...)
+	if app.ReloadFails {
+		return map[string]interface{}{"qReturn": false}, nil
+	}
+	if app.ReloadTables != nil {
+		app.Tables = app.ReloadTables
+	}
+	return map[string]interface{}{"qReturn": true}, nil
+}
+
+func doSave(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	session.app.Saved++
+	return map[string]interface{}{}, nil
+}
+
+// getTablesAndKeys returns the data model, fields occurring in more than one table are keys
+func getTablesAndKeys(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	tablesByField := map[string][]string{}
+	for _, table := range session.app.Tables {
+		for _, field := range table.Fields {
+			tablesByField[field] = append(tablesByField[field], table.Name)
+		}
+	}
+	tables := []map[string]interface{}{}
+	for _, table := range session.app.Tables {
+		fields := []map[string]interface{}{}
+		for _, field := range table.Fields {
+			keyType := "NOT_KEY"
+			if len(tablesByField[field]) > 1 {
+				keyType = "ANY_KEY"
+			}
+			fields = append(fields, map[string]interface{}{
+				"qName":                   field,
+				"qOriginalFields":         []string{},
+				"qPresent":                true,
+				"qInformationDensity":     1,
+				"qnNonNulls":              table.Rows,
+				"qnRows":                  table.Rows,
+				"qSubsetRatio":            1,
+				"qnTotalDistinctValues":   table.Rows,
+				"qnPresentDistinctValues": table.Rows,
+				"qKeyType":                keyType,
+				"qTags":                   []string{},
+			})
+		}
+		tables = append(tables, map[string]interface{}{"qName": table.Name, "qNoOfRows": table.Rows, "qFields": fields})
+	}
+	keys := []map[string]interface{}{}
+	for _, field := range sortedIDs(tablesByField) {
+		if len(tablesByField[field]) > 1 {
+			keys = append(keys, map[string]interface{}{"qKeyFields": []string{field}, "qTables": tablesByField[field]})
+		}
+	}
+	return map[string]interface{}{"qtr": tables, "qk": keys}, nil
+}
+
+func getAppProperties(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	return map[string]interface{}{"qProp": session.app.Properties}, nil
+}
+
+func setAppProperties(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	properties := map[string]interface{}{}
+	if err := param(params, 0, &properties); err != nil {
+		return nil, err
+	}
+	session.app.Properties = properties
+	if title, ok := properties["qTitle"].(string); ok {
+		session.app.Title = title
+	}
+	return map[string]interface{}{}, nil
+}
+
+func getAppLayout(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	app := session.app
+	return map[string]interface{}{"qLayout": map[string]interface{}{
+		"qTitle":     app.Title,
+		"qFileName":  app.ID,
+		"qHasScript": app.Script != "",
+		"qHasData":   len(app.Tables) > 0,
+	}}, nil
+}
+
+func getConnections(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	connections := []map[string]interface{}{}
+	for _, id := range sortedIDs(session.app.Connections) {
+		connection := withoutSecrets(session.app.Connections[id])
+		connection["qId"] = id
+		connections = append(connections, connection)
+	}
+	return map[string]interface{}{"qConnections": connections}, nil
+}
+
+func createConnection(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	connection := map[string]interface{}{}
+	if err := param(params, 0, &connection); err != nil {
+		return nil, err
+	}
+	for _, existing := range session.app.Connections {
+		if existing["qName"] == connection["qName"] {
+			return nil, &Error{Code: 2000, Message: fmt.Sprintf("Connection already exists: %v", connection["qName"])}
+		}
+	}
+	id := fmt.Sprintf("connection-%d", len(session.app.Connections)+1)
+	session.app.Connections[id] = connection
+	return map[string]interface{}{"qConnectionId": id}, nil
+}
+
+func modifyConnection(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	var id string
+	connection := map[string]interface{}{}
+	if err := param(params, 0, &id); err != nil {
+		return nil, err
+	}
+	if err := param(params, 1, &connection); err != nil {
+		return nil, err
+	}
+	if session.app.Connections[id] == nil {
+		return nil, &Error{Code: 2, Parameter: id, Message: "Object not found"}
+	}
+	session.app.Connections[id] = connection
+	return map[string]interface{}{}, nil
+}
+
+func deleteConnection(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	var id string
+	if err := param(params, 0, &id); err != nil {
+		return nil, err
+	}
+	delete(session.app.Connections, id)
+	return map[string]interface{}{}, nil
+}
+
+func checkExpression(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	return map[string]interface{}{"qErrorMsg": "", "qBadFieldNames": []interface{}{}, "qDangerousFieldNames": []interface{}{}}, nil
+}
+
+// createEntity creates an entity of the kind from the properties in the first parameter. Entities are created
+// with a generated id if the properties have none.
+func createEntity(kind, qType string, sessionObject bool) method {
+	return func(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+		properties := map[string]interface{}{}
+		if err := param(params, 0, &properties); err != nil {
+			return nil, err
+		}
+		info, _ := properties["qInfo"].(map[string]interface{})
+		if info == nil {
+			info = map[string]interface{}{"qType": kind}
+			properties["qInfo"] = info
+		}
+		id, _ := info["qId"].(string)
+		if id == "" {
+			session.engine.nextID++
+			id = fmt.Sprintf("%s-%d", kind, session.engine.nextID)
+			info["qId"] = id
+		}
+		entities := session.app.Entities
+		if sessionObject {
+			entities = session.sessionObjects
+		}
+		if entities[id] != nil {
+			return nil, &Error{Code: 4, Parameter: id, Message: "Object already exists"}
+		}
+		entity := &Entity{Kind: kind, Properties: properties}
+		entities[id] = entity
+		return map[string]interface{}{"qInfo": info, "qReturn": session.newHandle(&handleTarget{qType: qType, id: id, entity: entity})}, nil
+	}
+}
+
+// getEntity returns a reference to the entity with the id, or an empty reference with handle 0 if there is none
+// like the engine does
+func getEntity(kind, qType string) method {
+	return func(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+		var id string
+		if err := param(params, 0, &id); err != nil {
+			return nil, err
+		}
+		entity := session.sessionObjects[id]
+		if entity == nil {
+			entity = session.app.Entities[id]
+		}
+		if entity == nil || entity.Kind != kind {
+			return map[string]interface{}{"qReturn": map[string]interface{}{}}, nil
+		}
+		return map[string]interface{}{"qReturn": session.newHandle(&handleTarget{qType: qType, id: id, entity: entity})}, nil
+	}
+}
+
+func getVariableByName(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	var name string
+	if err := param(params, 0, &name); err != nil {
+		return nil, err
+	}
+	for id, entity := range session.app.Entities {
+		if entity.Kind == "variable" && entity.Properties["qName"] == name {
+			return map[string]interface{}{"qReturn": session.newHandle(&handleTarget{qType: "GenericVariable", id: id, entity: entity})}, nil
+		}
+	}
+	return map[string]interface{}{"qReturn": map[string]interface{}{}}, nil
+}
+
+func destroyEntity(kind string) method {
+	return func(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+		var id string
+		if err := param(params, 0, &id); err != nil {
+			return nil, err
+		}
+		entity := session.app.Entities[id]
+		if entity == nil || entity.Kind != kind {
+			return map[string]interface{}{"qSuccess": false}, nil
+		}
+		delete(session.app.Entities, id)
+		return map[string]interface{}{"qSuccess": true}, nil
+	}
+}
+
+func destroySessionObject(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	var id string
+	if err := param(params, 0, &id); err != nil {
+		return nil, err
+	}
+	_, found := session.sessionObjects[id]
+	delete(session.sessionObjects, id)
+	return map[string]interface{}{"qSuccess": found}, nil
+}
+
+func destroyVariableByName(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	var name string
+	if err := param(params, 0, &name); err != nil {
+		return nil, err
+	}
+	for id, entity := range session.app.Entities {
+		if entity.Kind == "variable" && entity.Properties["qName"] == name {
+			delete(session.app.Entities, id)
+			return map[string]interface{}{"qSuccess": true}, nil
+		}
+	}
+	return map[string]interface{}{"qSuccess": false}, nil
+}
+
+func getProperties(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	return map[string]interface{}{"qProp": target.entity.Properties}, nil
+}
+
+// setProperties replaces the properties, the id can not be changed
+func setProperties(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	properties := map[string]interface{}{}
+	if err := param(params, 0, &properties); err != nil {
+		return nil, err
+	}
+	properties["qInfo"] = target.entity.Properties["qInfo"]
+	target.entity.Properties = properties
+	return map[string]interface{}{}, nil
+}
+
+func getInfo(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	return map[string]interface{}{"qInfo": target.entity.Properties["qInfo"]}, nil
+}
+
+func getFullPropertyTree(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	children := target.entity.Children
+	if children == nil {
+		children = []interface{}{}
+	}
+	return map[string]interface{}{"qPropEntry": map[string]interface{}{
+		"qProperty":            target.entity.Properties,
+		"qChildren":            children,
+		"qEmbeddedSnapshotRef": nil,
+	}}, nil
+}
+
+func setFullPropertyTree(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	entry := struct {
+		Property map[string]interface{} `json:"qProperty"`
+		Children []interface{}          `json:"qChildren"`
+	}{}
+	if err := param(params, 0, &entry); err != nil {
+		return nil, err
+	}
+	if entry.Property != nil {
+		entry.Property["qInfo"] = target.entity.Properties["qInfo"]
+		target.entity.Properties = entry.Property
+	}
+	target.entity.Children = entry.Children
+	return map[string]interface{}{}, nil
+}
+
+// getChildInfos returns the infos of the children set with SetFullPropertyTree, children are not objects of
+// their own in the fake engine
+func getChildInfos(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	infos := []interface{}{}
+	for _, child := range target.entity.Children {
+		if child, ok := child.(map[string]interface{}); ok {
+			if property, ok := child["qProperty"].(map[string]interface{}); ok {
+				infos = append(infos, property["qInfo"])
+			}
+		}
+	}
+	return map[string]interface{}{"qInfos": infos}, nil
+}
+
+// getLayout returns the properties as layout, with the lists of entities and fields requested by list
+// definitions evaluated. Hypercubes are not calculated.
+func getLayout(session *Session, target *handleTarget, params []json.RawMessage) (interface{}, error) {
+	layout := map[string]interface{}{}
+	for key, value := range target.entity.Properties {
+		layout[key] = value
+	}
+	if target.entity.Kind == "variable" {
+		layout["qText"] = target.entity.Properties["qDefinition"]
+	}
+	lists := map[string]struct{ list, kind string }{
+		"qMeasureListDef":   {"qMeasureList", "measure"},
+		"qDimensionListDef": {"qDimensionList", "dimension"},
+		"qVariableListDef":  {"qVariableList", "variable"},
+		"qBookmarkListDef":  {"qBookmarkList", "bookmark"},
+	}
+	for def, list := range lists {
+		if listDef, ok := target.entity.Properties[def].(map[string]interface{}); ok {
+			delete(layout, def)
+			layout[list.list] = map[string]interface{}{"qItems": session.listItems(list.kind, listDef["qData"])}
+		}
+	}
+	if _, ok := target.entity.Properties["qFieldListDef"]; ok {
+		delete(layout, "qFieldListDef")
+		layout["qFieldList"] = map[string]interface{}{"qItems": session.fieldItems()}
+	}
+	return map[string]interface{}{"qLayout": layout}, nil
+}
+
+// listItems returns the list items of the entities of the kind with the data resolved from the entity properties
+func (s *Session) listItems(kind string, data interface{}) []map[string]interface{} {
+	items := []map[string]interface{}{}
+	for _, id := range sortedIDs(s.app.Entities) {
+		entity := s.app.Entities[id]
+		if entity.Kind != kind {
+			continue
+		}
+		item := map[string]interface{}{
+			"qInfo": entity.Properties["qInfo"],
+			"qMeta": entity.Properties["qMetaDef"],
+			"qData": resolveData(entity.Properties, data),
+		}
+		if kind == "variable" {
+			item["qName"] = entity.Properties["qName"]
+			item["qDefinition"] = entity.Properties["qDefinition"]
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func (s *Session) fieldItems() []map[string]interface{} {
+	tablesByField := map[string][]string{}
+	for _, table := range s.app.Tables {
+		for _, field := range table.Fields {
+			tablesByField[field] = append(tablesByField[field], table.Name)
+		}
+	}
+	items := []map[string]interface{}{}
+	for _, field := range sortedIDs(tablesByField) {
+		items = append(items, map[string]interface{}{"qName": field, "qSrcTables": tablesByField[field]})
+	}
+	return items
+}
+
+// resolveData replaces the json pointers, like /qMetaDef/title, in the data of a list definition with the
+// values they point to in the properties
+func resolveData(properties map[string]interface{}, data interface{}) interface{} {
+	switch data := data.(type) {
+	case map[string]interface{}:
+		resolved := map[string]interface{}{}
+		for key, value := range data {
+			resolved[key] = resolveData(properties, value)
+		}
+		return resolved
+	case string:
+		if !strings.HasPrefix(data, "/") {
+			return data
+		}
+		var value interface{} = properties
+		for _, token := range strings.Split(data[1:], "/") {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			value = object[token]
+		}
+		return value
+	}
+	return data
+}
+
+// withoutSecrets returns a copy of the connection without the credentials, which the engine never returns
+func withoutSecrets(connection map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range connection {
+		if key != "qPassword" {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+func sortedIDs(m interface{}) []string {
+	ids := []string{}
+	switch m := m.(type) {
+	case map[string]*App:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	case map[string]*Entity:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	case map[string]map[string]interface{}:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	case map[string][]string:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}